@@ -0,0 +1,66 @@
+// Package config reads the pssh config file of flag defaults
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Config holds defaults for pssh's flags. Unset fields are zero, leaving the
+// built in default in place.
+type Config struct {
+	// SSHConfig is the default for --ssh-config.
+	SSHConfig string `toml:"ssh_config"`
+	// ConnectTemplate is the default for --connect-template.
+	ConnectTemplate string `toml:"connect_template"`
+	// Loop is the default for --loop.
+	Loop *bool `toml:"loop"`
+	// RetryDelay is the default for --retry-delay, e.g. "5s".
+	RetryDelay time.Duration `toml:"retry_delay"`
+	// Theme is the TUI's colours, reloaded with ctrl+l while it's open.
+	Theme Theme `toml:"theme"`
+}
+
+// Theme holds the TUI's colours, as ANSI 256 colour numbers or hex codes.
+type Theme struct {
+	// Border colours the table border.
+	Border string `toml:"border"`
+	// SelectedForeground colours the text of the selected row.
+	SelectedForeground string `toml:"selected_foreground"`
+	// SelectedBackground colours the background of the selected row.
+	SelectedBackground string `toml:"selected_background"`
+}
+
+// Path returns the location of the config file, honouring $XDG_CONFIG_HOME.
+func Path() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("could not get user config directory: %w", err)
+	}
+
+	return filepath.Join(dir, "pssh", "config.toml"), nil
+}
+
+// Load reads the config file. A missing file yields an empty Config.
+func Load() (*Config, error) {
+	fp, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	var c Config
+	if _, err := toml.DecodeFile(fp, &c); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &Config{}, nil
+		}
+
+		return nil, fmt.Errorf("could not read config file %s: %w", fp, err)
+	}
+
+	return &c, nil
+}
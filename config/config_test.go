@@ -0,0 +1,68 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	// A missing file leaves every default in place
+	c, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *c != (Config{}) {
+		t.Errorf("Load without a file = %+v, want it empty", *c)
+	}
+
+	path := filepath.Join(dir, "pssh", "config.toml")
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	const file = `ssh_config = "~/.ssh/work"
+connect_template = "mosh {{.Name}}"
+loop = false
+retry_delay = "5s"
+`
+	if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err = Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.SSHConfig != "~/.ssh/work" || c.ConnectTemplate != "mosh {{.Name}}" || c.RetryDelay != 5*time.Second {
+		t.Errorf("Load = %+v", *c)
+	}
+
+	if c.Loop == nil || *c.Loop {
+		t.Errorf("loop = %v, want set to false", c.Loop)
+	}
+}
+
+func TestLoadMalformed(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	path := filepath.Join(dir, "pssh", "config.toml")
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte("loop = maybe\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(); err == nil {
+		t.Error("Load succeeded on a malformed file")
+	}
+}
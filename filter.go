@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/pix-xip/pssh/ssh"
+)
+
+// filterHosts pipes hosts through the filter command run with sh, as JSON
+// lines like pssh list --jsonl, and returns the hosts it prints the names of,
+// one per line, in the order printed. Unknown names are ignored.
+func filterHosts(filter string, hosts []*ssh.Host) ([]*ssh.Host, error) {
+	var in bytes.Buffer
+	if err := writeHostsJSONL(&in, hosts); err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command("sh", "-c", filter)
+	cmd.Stdin = &in
+	cmd.Stderr = os.Stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("filter command failed: %w", err)
+	}
+
+	byName := make(map[string]*ssh.Host, len(hosts))
+	for _, h := range hosts {
+		byName[h.Name] = h
+	}
+
+	var selected []*ssh.Host
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		h, ok := byName[strings.TrimSpace(scanner.Text())]
+		if !ok {
+			continue
+		}
+
+		selected = append(selected, h)
+		// Each host is listed once, however many times it's printed
+		delete(byName, h.Name)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read filter output: %w", err)
+	}
+
+	return selected, nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/pix-xip/pssh/ssh"
+)
+
+func TestFilterHosts(t *testing.T) {
+	hosts := []*ssh.Host{{Name: "web1"}, {Name: "web2"}, {Name: "db1"}}
+
+	tests := []struct {
+		name   string
+		filter string
+		want   []string
+	}{
+		{"subset in printed order", "printf 'db1\\nweb1\\n'", []string{"db1", "web1"}},
+		{"reads the hosts", `grep -o '"name":"web[0-9]*"' | cut -d'"' -f4`, []string{"web1", "web2"}},
+		{"unknown names and repeats", "printf 'cache\\n web2 \\nweb2\\n'", []string{"web2"}},
+		{"nothing", "true", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := filterHosts(tt.filter, hosts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var names []string
+			for _, h := range got {
+				names = append(names, h.Name)
+			}
+
+			if !slices.Equal(names, tt.want) {
+				t.Errorf("filtered to %q, want %q", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterHostsFails(t *testing.T) {
+	if _, err := filterHosts("echo web1; exit 3", []*ssh.Host{{Name: "web1"}}); err == nil {
+		t.Error("no error when the filter command exited non-zero")
+	}
+}
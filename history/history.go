@@ -0,0 +1,189 @@
+// Package history records the connections made with pssh
+package history
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/pix-xip/pssh/state"
+)
+
+// maxEntries caps how many connections are kept, dropping the oldest.
+const maxEntries = 1000
+
+// Entry is a single connection to a host.
+type Entry struct {
+	// Host is the name of the host connected to.
+	Host string `json:"host"`
+	// Time is when the connection started.
+	Time time.Time `json:"time"`
+	// Duration is how long the session lasted.
+	Duration time.Duration `json:"duration"`
+	// ExitStatus is the exit status of the command, -1 if it didn't exit.
+	ExitStatus int `json:"exit_status"`
+}
+
+// Path returns the location of the history file, next to the state file.
+func Path() (string, error) {
+	dir, err := state.Dir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "history.json"), nil
+}
+
+// Entries reads the recorded connections, oldest first. A missing file yields
+// no entries.
+func Entries() ([]Entry, error) {
+	fp, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Clean(fp))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("could not read history file %s: %w", fp, err)
+	}
+
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("could not decode history file %s: %w", fp, err)
+	}
+
+	return entries, nil
+}
+
+// Record records a connection, creating the history file if needed.
+func Record(e Entry) error {
+	entries, err := Entries()
+	if err != nil {
+		return err
+	}
+
+	entries = append(entries, e)
+	if len(entries) > maxEntries {
+		entries = entries[len(entries)-maxEntries:]
+	}
+
+	return save(entries)
+}
+
+func save(entries []Entry) error {
+	fp, err := Path()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fp), 0o700); err != nil {
+		return fmt.Errorf("could not create state directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode history: %w", err)
+	}
+
+	if err := os.WriteFile(fp, data, 0o600); err != nil {
+		return fmt.Errorf("could not write history file %s: %w", fp, err)
+	}
+
+	return nil
+}
+
+// sshFailed is the exit status ssh uses for its own errors, as opposed to the
+// remote command's.
+const sshFailed = 255
+
+// Counts returns how many times each host was connected to. Attempts where
+// ssh itself failed, or which didn't exit, aren't counted.
+func Counts(entries []Entry) map[string]int {
+	counts := make(map[string]int)
+
+	for _, e := range entries {
+		if e.ExitStatus == sshFailed || e.ExitStatus < 0 {
+			continue
+		}
+
+		counts[e.Host]++
+	}
+
+	return counts
+}
+
+// Stat is a summary of the connections to a host.
+type Stat struct {
+	// Count is how many times the host was connected to.
+	Count int
+	// Last is when the host was last connected to.
+	Last time.Time
+}
+
+// Load returns a Stat for each host connected to, counting connections as
+// Counts does. A missing file yields no stats.
+func Load() (map[string]Stat, error) {
+	entries, err := Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make(map[string]Stat)
+
+	for _, e := range entries {
+		if e.ExitStatus == sshFailed || e.ExitStatus < 0 {
+			continue
+		}
+
+		st := stats[e.Host]
+		st.Count++
+		if e.Time.After(st.Last) {
+			st.Last = e.Time
+		}
+
+		stats[e.Host] = st
+	}
+
+	return stats, nil
+}
+
+// WriteCSV writes entries as CSV with a header row. Timestamps are RFC 3339
+// and durations are whole seconds.
+func WriteCSV(w io.Writer, entries []Entry) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write([]string{"host", "timestamp", "duration", "exit_status"}); err != nil {
+		return fmt.Errorf("could not write csv: %w", err)
+	}
+
+	for _, e := range entries {
+		record := []string{
+			e.Host,
+			e.Time.Format(time.RFC3339),
+			strconv.FormatInt(int64(e.Duration.Round(time.Second)/time.Second), 10),
+			strconv.Itoa(e.ExitStatus),
+		}
+
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("could not write csv: %w", err)
+		}
+	}
+
+	cw.Flush()
+
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("could not write csv: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package history
+
+import (
+	"maps"
+	"testing"
+	"time"
+)
+
+func TestCounts(t *testing.T) {
+	entries := []Entry{
+		{Host: "web1", ExitStatus: 0},
+		{Host: "web1", ExitStatus: 1},
+		{Host: "db1", ExitStatus: 0},
+		// ssh itself failed, or the session didn't exit
+		{Host: "db1", ExitStatus: 255},
+		{Host: "web2", ExitStatus: -1},
+	}
+
+	if got, want := Counts(entries), map[string]int{"web1": 2, "db1": 1}; !maps.Equal(got, want) {
+		t.Errorf("Counts = %v, want %v", got, want)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{Host: "web1", Time: start, Duration: time.Minute},
+		{Host: "db1", Time: start.Add(time.Hour)},
+		{Host: "web1", Time: start.Add(2 * time.Hour), ExitStatus: 1},
+		// ssh failing to connect, or not running at all, isn't a connection
+		{Host: "web1", Time: start.Add(3 * time.Hour), ExitStatus: sshFailed},
+		{Host: "cache", Time: start, ExitStatus: -1},
+	}
+
+	for _, e := range entries {
+		if err := Record(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != len(entries) {
+		t.Fatalf("recorded %d entries, want %d", len(got), len(entries))
+	}
+
+	for i := range got {
+		if got[i].Host != entries[i].Host || !got[i].Time.Equal(entries[i].Time) || got[i].ExitStatus != entries[i].ExitStatus {
+			t.Errorf("entry %d = %+v, want %+v", i, got[i], entries[i])
+		}
+	}
+
+	stats, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]Stat{
+		"web1": {Count: 2, Last: start.Add(2 * time.Hour)},
+		"db1":  {Count: 1, Last: start.Add(time.Hour)},
+	}
+
+	if len(stats) != len(want) {
+		t.Errorf("stats = %v, want %v", stats, want)
+	}
+
+	for host, w := range want {
+		if s := stats[host]; s.Count != w.Count || !s.Last.Equal(w.Last) {
+			t.Errorf("stats[%s] = %+v, want %+v", host, s, w)
+		}
+	}
+}
+
+func TestRecordKeepsNewest(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	start := time.Now()
+	for i := range maxEntries + 5 {
+		if err := Record(Entry{Host: "web1", Time: start.Add(time.Duration(i) * time.Second)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != maxEntries {
+		t.Fatalf("kept %d entries, want %d", len(entries), maxEntries)
+	}
+
+	if oldest := start.Add(5 * time.Second); !entries[0].Time.Equal(oldest) {
+		t.Errorf("oldest entry at %s, want %s", entries[0].Time, oldest)
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	stats, err := Load()
+	if err != nil {
+		t.Fatalf("Load with no history file: %v", err)
+	}
+
+	if len(stats) != 0 {
+		t.Errorf("Load with no history file = %v, want none", stats)
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pix-xip/go-command"
+	"github.com/pix-xip/pssh/history"
+)
+
+// RunHistory prints the recorded connections, oldest first.
+func RunHistory(_ context.Context, fs *flag.FlagSet, _ []string) error {
+	entries, err := history.Entries()
+	if err != nil {
+		return err
+	}
+
+	if command.Lookup[bool](fs, "csv") {
+		return history.WriteCSV(os.Stdout, entries)
+	}
+
+	for _, e := range entries {
+		if _, err := fmt.Printf("%s  %-30s %10s  exit %d\n",
+			e.Time.Format(time.DateTime), e.Host, e.Duration.Round(time.Second), e.ExitStatus); err != nil {
+			return fmt.Errorf("could not write history: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"cmp"
+	"os"
+	"os/exec"
+	"strconv"
+
+	"github.com/charmbracelet/log"
+
+	"github.com/pix-xip/pssh/ssh"
+)
+
+// runAuditHook runs hook with sh after a session with host using vars ends,
+// with the host, user and exit status in PSSH_HOST, PSSH_USER and PSSH_EXIT. A
+// failing hook shouldn't stop anything, so errors are only logged.
+func runAuditHook(hook string, host *ssh.Host, vars ssh.CmdVars, status int) {
+	cmd := exec.Command("sh", "-c", hook)
+	cmd.Env = append(os.Environ(), auditEnv(host, vars, status)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		log.Warn("audit hook failed", "err", err)
+	}
+}
+
+// auditEnv is the environment describing a finished session for the audit
+// hook. The user is the one connected as, so an overriding user wins.
+func auditEnv(host *ssh.Host, vars ssh.CmdVars, status int) []string {
+	return []string{
+		"PSSH_HOST=" + host.Name,
+		"PSSH_USER=" + cmp.Or(vars.Override.User, host.User),
+		"PSSH_EXIT=" + strconv.Itoa(status),
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/pix-xip/pssh/ssh"
+)
+
+func TestAuditEnv(t *testing.T) {
+	host := &ssh.Host{Name: "web1", User: "deploy"}
+
+	tests := []struct {
+		name   string
+		vars   ssh.CmdVars
+		status int
+		want   []string
+	}{
+		{"host user", ssh.CmdVars{}, 0, []string{"PSSH_HOST=web1", "PSSH_USER=deploy", "PSSH_EXIT=0"}},
+		{
+			"override user",
+			ssh.CmdVars{Override: ssh.Override{User: "root", Port: "2222"}},
+			255,
+			[]string{"PSSH_HOST=web1", "PSSH_USER=root", "PSSH_EXIT=255"},
+		},
+		{"override port only", ssh.CmdVars{Override: ssh.Override{Port: "2222"}}, -1, []string{"PSSH_HOST=web1", "PSSH_USER=deploy", "PSSH_EXIT=-1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := auditEnv(host, tt.vars, tt.status); !slices.Equal(got, tt.want) {
+				t.Errorf("auditEnv = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunAuditHook(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "audit")
+
+	runAuditHook(`echo "$PSSH_HOST $PSSH_USER $PSSH_EXIT" > `+out, &ssh.Host{Name: "web1", User: "deploy"}, ssh.CmdVars{}, 1)
+
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "web1 deploy 1\n"; string(got) != want {
+		t.Errorf("hook wrote %q, want %q", got, want)
+	}
+
+	// A failing hook is only logged
+	runAuditHook("exit 1", &ssh.Host{Name: "web1"}, ssh.CmdVars{}, 0)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/log"
+
+	"github.com/pix-xip/pssh/ssh"
+)
+
+// forHost returns opts and vars with the flags from a `#pssh: --flag value`
+// comment in host's block applied. Flags given on the command line take
+// precedence over the comment, which in turn beats the config file and
+// built in defaults. Only flags affecting a single connection can be set;
+// others are warned about and ignored, so the host can still be connected to.
+func (opts connectOptions) forHost(host *ssh.Host, vars ssh.CmdVars) (connectOptions, ssh.CmdVars, error) {
+	args, err := host.ConnectFlags()
+	if err != nil || len(args) == 0 {
+		return opts, vars, err
+	}
+
+	fs := flag.NewFlagSet("#pssh", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.BoolVar(&opts.reattach, "reattach", opts.reattach, "")
+	fs.DurationVar(&opts.retry.delay, "retry-delay", opts.retry.delay, "")
+	fs.IntVar(&opts.retry.max, "retry-max", opts.retry.max, "")
+	fs.BoolVar(&opts.retry.backoff, "retry-backoff", opts.retry.backoff, "")
+	fs.DurationVar(&opts.retry.backoffCap, "retry-backoff-cap", opts.retry.backoffCap, "")
+	fs.StringVar((*string)(&vars.Jump), "jump", string(vars.Jump), "")
+	fs.StringVar((*string)(&vars.Command), "command", string(vars.Command), "")
+
+	args, unknown := knownFlags(fs, args)
+	if len(unknown) > 0 {
+		log.Warn("ignoring unknown #pssh flags", "host", host.Name, "flags", strings.Join(unknown, " "))
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return opts, vars, fmt.Errorf("invalid #pssh flags for %s: %w", host.Name, err)
+	}
+
+	if fs.NArg() > 0 {
+		return opts, vars, fmt.Errorf("invalid #pssh flags for %s: unexpected argument %q", host.Name, fs.Arg(0))
+	}
+
+	for name, value := range opts.cliFlags {
+		if fs.Lookup(name) == nil {
+			continue
+		}
+
+		if err := fs.Set(name, value); err != nil {
+			return opts, vars, fmt.Errorf("could not restore --%s: %w", name, err)
+		}
+	}
+
+	return opts, vars, nil
+}
+
+// knownFlags splits args into the flags fs defines, with their values, and the
+// unknown ones left out. An unknown flag's value is taken to be the next
+// argument, unless that starts with -, e.g. "--verbose 2".
+func knownFlags(fs *flag.FlagSet, args []string) (known, unknown []string) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") || arg == "--" {
+			// Arguments are left for the caller to reject
+			return append(known, args[i:]...), unknown
+		}
+
+		name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		f := fs.Lookup(name)
+		valueNext := !hasValue && i+1 < len(args)
+
+		if f == nil {
+			unknown = append(unknown, arg)
+
+			if valueNext && !strings.HasPrefix(args[i+1], "-") {
+				unknown = append(unknown, args[i+1])
+				i++
+			}
+
+			continue
+		}
+
+		known = append(known, arg)
+
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); valueNext && !(ok && b.IsBoolFlag()) {
+			known = append(known, args[i+1])
+			i++
+		}
+	}
+
+	return known, unknown
+}
+
+// setFlags returns the value of each flag given on the command line.
+func setFlags(fs *flag.FlagSet) map[string]string {
+	set := make(map[string]string)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = f.Value.String()
+	})
+
+	return set
+}
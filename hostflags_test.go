@@ -0,0 +1,119 @@
+package main
+
+import (
+	"flag"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kevinburke/ssh_config"
+	"github.com/pix-xip/pssh/ssh"
+)
+
+// annotatedHost is a host whose block has the given comment.
+func annotatedHost(t *testing.T, comment string) *ssh.Host {
+	t.Helper()
+
+	cfg, err := ssh_config.Decode(strings.NewReader("Host web1\n  " + comment + "\n  HostName 10.0.0.1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return ssh.NewHost(cfg.Hosts[1])
+}
+
+func TestForHost(t *testing.T) {
+	host := annotatedHost(t, "#pssh: --retry-max 2 --retry-delay 10s --jump bastion --reattach")
+	defaults := connectOptions{retry: retryOptions{delay: 2 * time.Second, max: 0}}
+
+	opts, vars, err := defaults.forHost(host, ssh.CmdVars{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if opts.retry.max != 2 || opts.retry.delay != 10*time.Second || !opts.reattach || vars.Jump != "bastion" {
+		t.Errorf("from the comment got %+v and jump %q", opts.retry, vars.Jump)
+	}
+
+	// Flags given on the command line win over the comment
+	fs := flag.NewFlagSet("pssh", flag.ContinueOnError)
+	fs.Int("retry-max", 0, "")
+	fs.String("jump", "", "")
+	fs.Bool("read-only", false, "")
+
+	if err := fs.Parse([]string{"--retry-max", "5", "--jump", "gateway", "--read-only"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cli := defaults
+	cli.retry.max = 5
+	cli.cliFlags = setFlags(fs)
+
+	opts, vars, err = cli.forHost(host, ssh.CmdVars{Jump: "gateway"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if opts.retry.max != 5 || vars.Jump != "gateway" {
+		t.Errorf("with flags got retry max %d and jump %q, want the command line's 5 and gateway", opts.retry.max, vars.Jump)
+	}
+
+	// Those not given on the command line still come from the comment
+	if opts.retry.delay != 10*time.Second {
+		t.Errorf("retry delay = %s, want the comment's 10s", opts.retry.delay)
+	}
+}
+
+func TestForHostWithoutComment(t *testing.T) {
+	opts := connectOptions{retry: retryOptions{max: 3}}
+
+	got, _, err := opts.forHost(annotatedHost(t, "# web server"), ssh.CmdVars{})
+	if err != nil || got.retry.max != 3 {
+		t.Errorf("forHost = %+v, %v, want the options unchanged", got.retry, err)
+	}
+}
+
+func TestForHostUnknownFlags(t *testing.T) {
+	host := annotatedHost(t, "#pssh: --force-tty --verbose 2 --retry-max 4 --quiet=yes --reattach")
+
+	opts, _, err := (connectOptions{}).forHost(host, ssh.CmdVars{})
+	if err != nil {
+		t.Fatalf("forHost with unknown flags: %v", err)
+	}
+
+	if opts.retry.max != 4 || !opts.reattach {
+		t.Errorf("got retry max %d and reattach %v, want the known flags applied", opts.retry.max, opts.reattach)
+	}
+}
+
+func TestKnownFlags(t *testing.T) {
+	fs := flag.NewFlagSet("#pssh", flag.ContinueOnError)
+	fs.Bool("reattach", false, "")
+	fs.Int("retry-max", 0, "")
+
+	tests := []struct {
+		args, known, unknown []string
+	}{
+		{[]string{"--retry-max", "2", "--reattach"}, []string{"--retry-max", "2", "--reattach"}, nil},
+		{[]string{"--verbose", "2", "--retry-max=3"}, []string{"--retry-max=3"}, []string{"--verbose", "2"}},
+		{[]string{"--force-tty", "--reattach"}, []string{"--reattach"}, []string{"--force-tty"}},
+		// A bool flag doesn't take the next argument as its value
+		{[]string{"--reattach", "web2"}, []string{"--reattach", "web2"}, nil},
+	}
+
+	for _, tt := range tests {
+		known, unknown := knownFlags(fs, tt.args)
+		if !slices.Equal(known, tt.known) || !slices.Equal(unknown, tt.unknown) {
+			t.Errorf("knownFlags(%q) = %q, %q, want %q, %q", tt.args, known, unknown, tt.known, tt.unknown)
+		}
+	}
+}
+
+func TestForHostInvalid(t *testing.T) {
+	for _, comment := range []string{"#pssh: --retry-max lots", "#pssh: web2", "#pssh: --reattach web2"} {
+		if _, _, err := (connectOptions{}).forHost(annotatedHost(t, comment), ssh.CmdVars{}); err == nil {
+			t.Errorf("forHost with %q succeeded, want an error", comment)
+		}
+	}
+}
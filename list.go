@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pix-xip/go-command"
+	"github.com/pix-xip/pssh/ssh"
+)
+
+// RunList prints the loaded hosts without starting the TUI. Hosts named by a
+// pattern, such as "*.internal", only hold options for others so aren't
+// listed, as in the TUI.
+func RunList(_ context.Context, fs *flag.FlagSet, _ []string) error {
+	hosts, err := hostLoader(fs, nil)()
+	if err != nil {
+		return err
+	}
+
+	hosts = slices.DeleteFunc(hosts, (*ssh.Host).IsPattern)
+
+	if command.Lookup[bool](fs, "redact") {
+		hosts = redactHosts(hosts)
+	}
+
+	if command.Lookup[bool](fs, "jsonl") {
+		return writeHostsJSONL(os.Stdout, hosts)
+	}
+
+	switch format := command.Lookup[string](fs, "format"); format {
+	case "names":
+		sep := "\n"
+		if command.Lookup[bool](fs, "null") {
+			sep = "\x00"
+		}
+
+		return writeHostNames(os.Stdout, hosts, sep)
+	case "json":
+		return writeHostsJSON(os.Stdout, hosts)
+	case "table":
+		return writeHostsTable(os.Stdout, hosts)
+	default:
+		return fmt.Errorf("invalid format %q, must be names, json or table", format)
+	}
+}
+
+// writeHostNames writes each host name followed by sep.
+func writeHostNames(w io.Writer, hosts []*ssh.Host, sep string) error {
+	for _, h := range hosts {
+		if _, err := fmt.Fprint(w, h.Name, sep); err != nil {
+			return fmt.Errorf("could not write host: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// writeHostsJSON writes the hosts as an indented JSON array.
+func writeHostsJSON(w io.Writer, hosts []*ssh.Host) error {
+	if hosts == nil {
+		// An empty array rather than null
+		hosts = []*ssh.Host{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(hosts); err != nil {
+		return fmt.Errorf("could not encode hosts: %w", err)
+	}
+
+	return nil
+}
+
+// writeHostsTable writes the hosts as a plain text table with aligned
+// columns, without any styling.
+func writeHostsTable(w io.Writer, hosts []*ssh.Host) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	if _, err := fmt.Fprintln(tw, "NAME\tUSER\tHOSTNAME\tPORT\tALIASES"); err != nil {
+		return fmt.Errorf("could not write hosts: %w", err)
+	}
+
+	for _, h := range hosts {
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+			h.Name, h.User, h.Hostname, h.Port, strings.Join(h.Aliases, ",")); err != nil {
+			return fmt.Errorf("could not write host %s: %w", h.Name, err)
+		}
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("could not write hosts: %w", err)
+	}
+
+	return nil
+}
+
+// writeHostsJSONL streams one JSON object per host per line, so large configs
+// never need encoding as a single array.
+func writeHostsJSONL(w io.Writer, hosts []*ssh.Host) error {
+	enc := json.NewEncoder(w)
+
+	for _, h := range hosts {
+		if err := enc.Encode(h); err != nil {
+			return fmt.Errorf("could not encode host %s: %w", h.Name, err)
+		}
+	}
+
+	return nil
+}
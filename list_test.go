@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/pix-xip/pssh/ssh"
+)
+
+func TestWriteHostNames(t *testing.T) {
+	hosts := []*ssh.Host{{Name: "web1"}, {Name: "db 1"}}
+
+	for sep, want := range map[string]string{
+		"\n":   "web1\ndb 1\n",
+		"\x00": "web1\x00db 1\x00",
+	} {
+		var out strings.Builder
+		if err := writeHostNames(&out, hosts, sep); err != nil {
+			t.Fatal(err)
+		}
+
+		if out.String() != want {
+			t.Errorf("separated by %q: wrote %q, want %q", sep, out.String(), want)
+		}
+	}
+}
+
+func TestWriteHostsJSONL(t *testing.T) {
+	hosts := []*ssh.Host{
+		{Name: "web1", User: "deploy", Hostname: "10.0.0.1"},
+		{Name: "db1", Aliases: []string{"db"}, Port: "2222"},
+	}
+
+	var out strings.Builder
+	if err := writeHostsJSONL(&out, hosts); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != len(hosts) {
+		t.Fatalf("wrote %d lines, want one per host:\n%s", len(lines), out.String())
+	}
+
+	for i, line := range lines {
+		var got struct {
+			Name string `json:"name"`
+			Port string `json:"port"`
+		}
+
+		if err := json.Unmarshal([]byte(line), &got); err != nil {
+			t.Fatalf("line %d %q: %v", i, line, err)
+		}
+
+		if got.Name != hosts[i].Name || got.Port != hosts[i].Port {
+			t.Errorf("line %d = %+v, want %s", i, got, hosts[i].Name)
+		}
+	}
+}
+
+func TestWriteHostsJSON(t *testing.T) {
+	hosts := []*ssh.Host{
+		{Name: "web1", User: "deploy", Hostname: "10.0.0.1", Aliases: []string{"www"}},
+		{Name: "db1", Port: "2222"},
+	}
+	hosts[0].SetOtherOptions(map[string]string{"forwardagent": "yes", "localforward": "8080 localhost:80"})
+
+	var out strings.Builder
+	if err := writeHostsJSON(&out, hosts); err != nil {
+		t.Fatal(err)
+	}
+
+	const want = `[
+  {
+    "name": "web1",
+    "aliases": [
+      "www"
+    ],
+    "user": "deploy",
+    "hostname": "10.0.0.1",
+    "options": {
+      "forwardagent": "yes",
+      "localforward": "8080 localhost:80"
+    }
+  },
+  {
+    "name": "db1",
+    "aliases": [],
+    "port": "2222"
+  }
+]
+`
+	if out.String() != want {
+		t.Errorf("wrote:\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	// No hosts is an empty array rather than null
+	out.Reset()
+	if err := writeHostsJSON(&out, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if out.String() != "[]\n" {
+		t.Errorf("wrote %q for no hosts, want an empty array", out.String())
+	}
+}
+
+func TestWriteHostsTable(t *testing.T) {
+	hosts := []*ssh.Host{
+		{Name: "web1", User: "deploy", Hostname: "10.0.0.1", Aliases: []string{"www", "frontend"}},
+		{Name: "database1", Hostname: "10.0.0.2", Port: "2222"},
+	}
+
+	var out strings.Builder
+	if err := writeHostsTable(&out, hosts); err != nil {
+		t.Fatal(err)
+	}
+
+	want := strings.Join([]string{
+		"NAME       USER    HOSTNAME  PORT  ALIASES",
+		"web1       deploy  10.0.0.1        www,frontend",
+		"database1          10.0.0.2  2222  ",
+		"",
+	}, "\n")
+	if out.String() != want {
+		t.Errorf("wrote:\n%q\nwant:\n%q", out.String(), want)
+	}
+}
@@ -1,30 +1,82 @@
 package main
 
 import (
+	"bufio"
+	"cmp"
 	"context"
 	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"net"
+	"os"
 	"os/exec"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/pix-xip/go-command"
+	"github.com/pix-xip/pssh/config"
+	"github.com/pix-xip/pssh/history"
 	"github.com/pix-xip/pssh/ssh"
 	"github.com/pix-xip/pssh/tui"
 )
 
-const defaultSSHConfig = "~/.ssh/config"
+const (
+	defaultSSHConfig = ssh.UserConfig
+	// Jump, Override, Identity and ExtraArgs go before the host so ssh treats them as
+	// options rather than as a remote command. They render empty when unset.
+	defaultTmpl = "ssh {{.Jump}} {{.Override}} {{.Identity}} {{.ExtraArgs}} {{.Name}} {{.Command}}"
+	// remoteTmuxTmpl attaches to an existing tmux session on the remote, or starts
+	// a new one, running --command in it if given. The remote command is quoted
+	// so ssh receives it as one argument.
+	remoteTmuxTmpl      = `ssh -t {{.Jump}} {{.Override}} {{.Identity}} {{.ExtraArgs}} {{.Name}} {{if .Command}}{{printf "tmux new %s" .Command | quote}}{{else}}"tmux attach || tmux new"{{end}}`
+	defaultDescriptions = "~/.ssh/hosts.desc"
+	defaultRetryDelay   = 2 * time.Second
+	// defaultRetryBackoffCap keeps backoff from waiting unreasonably long.
+	defaultRetryBackoffCap = time.Minute
+)
 
 var Version string
 
 func main() {
-	r := command.Root().Help("pssh is a TUI ssh manager").
-		Flags(func(fs *flag.FlagSet) {
-			fs.String("ssh-config", defaultSSHConfig, "path to ssh config file")
-		})
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	r := command.Root().Help("pssh is a TUI ssh manager\n\nArguments after -- are passed through to ssh, e.g. pssh -- -L 8080:localhost:80\n\nName a host to connect to it without the TUI, e.g. pssh web1, or pssh web1 -L 8080:localhost:80.\nAnything else opens the TUI searching for it, e.g. pssh prod").
+		Flags(rootFlags(cfg))
 
 	r.Action(RunTui)
+	r.SubCommand("list").
+		Flags(func(fs *flag.FlagSet) {
+			fs.String("format", "names", "output format: names, json (an array of hosts) or table")
+			fs.Bool("jsonl", false, "stream hosts as JSON, one object per line")
+			fs.Bool("redact", false, "mask host names, users and other identifying values, e.g. web***")
+			fs.Bool("null", false, "separate host names with NUL instead of newline, for xargs -0 or fzf --read0")
+		}).
+		Action(RunList).
+		Help("list hosts without starting the TUI")
+	r.SubCommand("render").
+		Flags(func(fs *flag.FlagSet) {
+			fs.String("template", defaultTmpl, "command `template` to render")
+			fs.String("host", "", "name or alias of the host to render the template for")
+		}).
+		Action(RunRender).
+		Help("print the command a template renders to for a host, without connecting")
+	r.SubCommand("set").
+		Flags(func(fs *flag.FlagSet) {
+			fs.Var(&stringList{}, "host", "name or alias of a host to change (repeatable)")
+		}).
+		Action(RunSet).
+		Help("set an option in the config block of each --host, e.g. pssh set --host web1 ForwardAgent no")
+	r.SubCommand("history").
+		Flags(func(fs *flag.FlagSet) {
+			fs.Bool("csv", false, "print as CSV: host, timestamp, duration (seconds), exit status")
+		}).
+		Action(RunHistory).
+		Help("show past connections")
 	r.SubCommand("version").Action(func(_ context.Context, _ *flag.FlagSet, _ []string) error {
 		log.Infof("pssh version %s", Version)
 		return nil
@@ -35,45 +87,519 @@ func main() {
 	}
 }
 
-func RunTui(_ context.Context, fs *flag.FlagSet, _ []string) error {
-	fp := command.Lookup[string](fs, "ssh-config")
+// rootFlags registers the root command's flags, which the subcommands share.
+// The config file only changes flag defaults, so flags given on the command
+// line still win.
+func rootFlags(cfg *config.Config) func(fs *flag.FlagSet) {
+	loop := true
+	if cfg.Loop != nil {
+		loop = *cfg.Loop
+	}
+
+	return func(fs *flag.FlagSet) {
+		fs.String("ssh-config", cmp.Or(cfg.SSHConfig, defaultSSHConfig), "path or https:// URL of the ssh config file")
+		fs.Var(&stringList{}, "profile", "load hosts from the named profile instead of --ssh-config (repeatable)")
+		fs.String("exec", "", "connect to `target` ([ssh://][user@]host[:port]) without the TUI")
+		fs.String("connect", "", "connect to `target` ([ssh://][user@]host[:port]) without looking it up in any config")
+		fs.String("watch", "", "wait for `target` ([user@]host[:port]) to accept connections, then connect to it")
+		fs.Duration("watch-interval", defaultWatchInterval, "how often --watch checks whether the host is up")
+		fs.Bool("print-only", false, "print the selected host name to stdout instead of connecting")
+		fs.String("connect-template", cmp.Or(cfg.ConnectTemplate, defaultTmpl), "command `template` run to connect, e.g. \"mosh {{.Name}}\"; one without {{.Jump}}, {{.Override}}, {{.Identity}}, {{.ExtraArgs}} or {{.Command}} ignores that setting")
+		fs.String("jump", "", "connect through this jump host (ssh -J)")
+		fs.String("command", "", "run this command on the host instead of a shell; ctrl+x runs it on every host shown")
+		fs.String("descriptions", defaultDescriptions, "path to a file of host descriptions, one \"name description\" per line")
+		fs.Bool("strict", false, "fail on unknown ssh_config options")
+		fs.String("start-on", string(tui.StartOnFirst), "row the cursor starts on: first or recent (last selected host)")
+		fs.Bool("group-by-prefix", false, "group hosts by the name prefix before the first '-'")
+		fs.Bool("resolve-effective", false, "show the user, hostname, port and identity files ssh would use, resolved across all matching Host blocks")
+		fs.Bool("show-counts", false, "show how many times each host has been connected to")
+		fs.String("matcher", string(tui.MatcherFuzzy), "search matching: fuzzy, or boundary to favour matches at the start of words")
+		fs.String("search-delimiter", " ", "text joining a host's fields into the text searched")
+		fs.Bool("no-cross-field", false, "require each word of the search to match within a single field")
+		fs.Bool("changed", false, "only show hosts added or changed since the last run")
+		fs.String("sort", "", "order to start the table in: name, user, hostname, port, domain, recent or frequent")
+		fs.Int("max-hosts", 0, "show at most this many hosts until the search narrows them down (0 shows all)")
+		fs.Bool("show-auth", false, "show a badge for whether each host likely uses a key (🔑) or a password (🔒)")
+		fs.Bool("show-identity", false, "show a column with each host's identity files")
+		fs.Bool("show-source", false, "show a column with the config file and line each host is defined at")
+		fs.Bool("debug-scores", false, "debug: show each row's fuzzy match score")
+		fs.Bool("explode-patterns", false, "list each pattern of a multi-pattern Host block as its own host")
+		fs.Bool("concrete-only", false, "hide hosts without a Hostname option, such as templates and fragments")
+		fs.String("filter-cmd", "", "shell `command` fed the hosts as JSON lines, printing the names of those to show, one per line")
+		fs.Bool("hide-empty", false, "hide placeholder hosts which set no options")
+		fs.Bool("only", false, "only load --ssh-config, ignoring the default user and system configs")
+		fs.Bool("auto-select", false, "connect as soon as the search matches a single host, without pressing enter")
+		fs.Duration("revert-search", 0, "restore the last matching search after this long when nothing matches (0 disables)")
+		fs.Bool("reattach", false, "offer to reconnect when a session exits cleanly")
+		fs.Bool("loop", loop, "return to the host list after a session ends")
+		fs.Duration("retry-delay", cmp.Or(cfg.RetryDelay, defaultRetryDelay), "how long to wait before retrying a failed connection")
+		fs.Bool("retry-backoff", false, "double the retry delay after each failed attempt, up to --retry-backoff-cap")
+		fs.Duration("retry-backoff-cap", defaultRetryBackoffCap, "longest delay between retries with --retry-backoff")
+		fs.Int("retry-max", 0, "give up after this many failed connection attempts (0 retries forever)")
+		fs.String("audit-hook", "", "shell command run after every session, with PSSH_HOST, PSSH_USER and PSSH_EXIT set")
+		fs.Bool("remote-tmux", false, "attach to (or create) a tmux session on the remote host")
+		fs.Bool("read-only", false, "disable editing the ssh config from the TUI")
+		fs.Bool("use-matched-alias", false, "connect using the alias the search matched instead of the host name")
+		fs.String("alias-format", string(ssh.AliasParen), "how aliases are shown: paren, comma or hidden")
+		fs.String("short-names", "", "domain suffix to strip from displayed host names (e.g. .prod.example.com)")
+	}
+}
+
+// connectTmpl returns the template to connect with. --remote-tmux replaces the
+// default, including one from config.toml, but not a --connect-template given
+// on the command line.
+func connectTmpl(fs *flag.FlagSet) string {
+	if _, set := setFlags(fs)["connect-template"]; command.Lookup[bool](fs, "remote-tmux") && !set {
+		return remoteTmuxTmpl
+	}
+
+	return command.Lookup[string](fs, "connect-template")
+}
+
+func RunTui(_ context.Context, fs *flag.FlagSet, args []string) error {
+	vars := ssh.CmdVars{
+		ExtraArgs: args,
+		Jump:      ssh.JumpHost(command.Lookup[string](fs, "jump")),
+		Command:   ssh.RemoteCommand(command.Lookup[string](fs, "command")),
+	}
+
+	tmpl := connectTmpl(fs)
+	if err := ssh.ValidateCmdTmpl(tmpl); err != nil {
+		return err
+	}
+
+	connOpts := connectOptions{
+		reattach:  command.Lookup[bool](fs, "reattach"),
+		auditHook: command.Lookup[string](fs, "audit-hook"),
+		cliFlags:  setFlags(fs),
+		retry: retryOptions{
+			delay:      command.Lookup[time.Duration](fs, "retry-delay"),
+			max:        command.Lookup[int](fs, "retry-max"),
+			backoff:    command.Lookup[bool](fs, "retry-backoff"),
+			backoffCap: command.Lookup[time.Duration](fs, "retry-backoff-cap"),
+		},
+	}
+
+	startOn, err := tui.ParseStartOn(command.Lookup[string](fs, "start-on"))
+	if err != nil {
+		return err
+	}
+
+	aliasFormat, err := ssh.ParseAliasFormat(command.Lookup[string](fs, "alias-format"))
+	if err != nil {
+		return err
+	}
+
+	sortBy, err := tui.ParseSortBy(command.Lookup[string](fs, "sort"))
+	if err != nil {
+		return err
+	}
+
+	matcher, err := tui.ParseMatcher(command.Lookup[string](fs, "matcher"))
+	if err != nil {
+		return err
+	}
+
+	theme, err := loadTheme()
+	if err != nil {
+		return err
+	}
+
+	diags := &diagnostics{}
+
+	opts := tui.Options{
+		StartOn:           startOn,
+		SSHConfig:         primaryConfig(fs),
+		LoadHosts:         hostLoader(fs, diags),
+		Diagnostics:       func() []ssh.Diagnostic { return diags.list },
+		GroupByPrefix:     command.Lookup[bool](fs, "group-by-prefix"),
+		Sort:              sortBy,
+		MaxHosts:          command.Lookup[int](fs, "max-hosts"),
+		ShowAuth:          command.Lookup[bool](fs, "show-auth"),
+		ShowIdentity:      command.Lookup[bool](fs, "show-identity"),
+		ShowSource:        command.Lookup[bool](fs, "show-source"),
+		ChangedOnly:       command.Lookup[bool](fs, "changed"),
+		DebugScores:       command.Lookup[bool](fs, "debug-scores"),
+		Matcher:           matcher,
+		SearchDelimiter:   command.Lookup[string](fs, "search-delimiter"),
+		FieldScopedSearch: command.Lookup[bool](fs, "no-cross-field"),
+		ShowCounts:        command.Lookup[bool](fs, "show-counts"),
+		ShortNameSuffix:   command.Lookup[string](fs, "short-names"),
+		UseMatchedAlias:   command.Lookup[bool](fs, "use-matched-alias"),
+		ReadOnly:          command.Lookup[bool](fs, "read-only"),
+		AliasFormat:       aliasFormat,
+		RevertSearchAfter: command.Lookup[time.Duration](fs, "revert-search"),
+		AutoSelect:        command.Lookup[bool](fs, "auto-select"),
+		Theme:             theme,
+		ReloadTheme:       loadTheme,
+		Tmpl:              tmpl,
+		Vars:              vars,
+	}
+
+	if target := command.Lookup[string](fs, "connect"); target != "" {
+		host, override := scratchHost(target)
+		vars.Override = override
+
+		return runSSH(host, tmpl, vars, connOpts)
+	}
+
+	if target := command.Lookup[string](fs, "exec"); target != "" {
+		host, override, err := resolveTarget(target, opts.LoadHosts)
+		if err != nil {
+			return err
+		}
+
+		vars.Override = override
+
+		return runSSH(host, tmpl, vars, connOpts)
+	}
+
+	if target := command.Lookup[string](fs, "watch"); target != "" {
+		host, override, err := resolveTarget(target, opts.LoadHosts)
+		if err != nil {
+			return err
+		}
+
+		vars.Override = override
+
+		waitForHost(host, vars, command.Lookup[time.Duration](fs, "watch-interval"), net.DialTimeout)
+
+		return runSSH(host, tmpl, vars, connOpts)
+	}
+
+	// A leading argument which isn't an ssh option names the host to
+	// connect to, e.g. pssh web1 -v, with the rest passed through to ssh.
+	// Anything else it matches opens the TUI searching for it, e.g. pssh prod.
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		term := args[0]
+		vars.ExtraArgs = args[1:]
+
+		hosts, err := opts.LoadHosts()
+		if err != nil {
+			return err
+		}
+
+		found := findHosts(hosts, term)
+
+		switch {
+		case len(found) == 1 && command.Lookup[bool](fs, "print-only"):
+			return printHost(os.Stdout, found[0])
+		case len(found) == 1:
+			return runSSH(found[0], tmpl, vars, connOpts)
+		case len(found) == 0 && !matchesAny(hosts, term):
+			return fmt.Errorf("no host matches %q in ssh config", term)
+		default:
+			opts.Search = term
+			opts.Vars = vars
+		}
+	}
+
+	if command.Lookup[bool](fs, "print-only") {
+		// Render the TUI on stderr so stdout only carries the host name,
+		// e.g. HOST=$(pssh --print-only)
+		opts.Tmpl = ""
+		opts.Output = os.Stderr
+
+		sel, err := tui.SelectHost(opts)
+		if err != nil || sel.Host == nil {
+			return err
+		}
+
+		return printHost(os.Stdout, sel.Host)
+	}
+
+	loop := command.Lookup[bool](fs, "loop")
 
 	for {
-		selectedHost := tui.SelectHost(fp)
-		if selectedHost == nil {
+		sel, err := tui.SelectHost(opts)
+		if err != nil {
+			return err
+		}
+
+		// Only the first time round starts filtered to the named host
+		opts.Search = ""
+
+		if len(sel.All) > 0 {
+			err := runAll(os.Stdout, sel.All, tmpl, sel.Vars)
+			if !loop {
+				return err
+			}
+
+			if err != nil {
+				log.Error("command failed on some hosts", "err", err)
+			}
+
+			continue
+		}
+
+		if sel.Host == nil {
 			// User quit the TUI
 			return nil
 		}
 
-		if err := runSSH(selectedHost); err != nil {
+		// Keep the chosen action for the next time round
+		opts.Action = sel.Action
+
+		if sel.Action == tui.ActionPrint {
+			return printCmd(os.Stdout, sel.Host, tmpl, sel.Vars)
+		}
+
+		err = runSSH(sel.Host, tmpl, sel.Vars, connOpts)
+		if !loop {
+			return err
+		}
+
+		if err != nil {
 			log.Error("unable to connect to host", "err", err)
 		}
 	}
 }
 
-func runSSH(host *ssh.Host) error {
-	tmpl := "ssh {{.Name}}" // TODO: make this configurable
-	// IMPROV: could also add a {{.Comamand}} from CLI to run commnds via connection?
+// diagnostics holds the problems found by the last load of hosts.
+type diagnostics struct {
+	list []ssh.Diagnostic
+}
+
+// hostLoader returns a function loading hosts as configured by the flags
+// shared by the TUI and subcommands: either from the named profiles or from
+// --ssh-config and the default configs, along with their descriptions, and
+// narrowed down by --filter-cmd. Problems found while loading are logged, and kept in diags if it isn't nil.
+func hostLoader(fs *flag.FlagSet, diags *diagnostics) func() ([]*ssh.Host, error) {
+	opts := ssh.LoadOptions{
+		ExplodePatterns:  command.Lookup[bool](fs, "explode-patterns"),
+		ResolveEffective: command.Lookup[bool](fs, "resolve-effective"),
+		Strict:           command.Lookup[bool](fs, "strict"),
+		ConcreteOnly:     command.Lookup[bool](fs, "concrete-only"),
+		HideEmpty:        command.Lookup[bool](fs, "hide-empty"),
+		Warn: func(d ssh.Diagnostic) {
+			log.Warn(d.String())
+
+			if diags != nil {
+				diags.list = append(diags.list, d)
+			}
+		},
+	}
+
+	load := func() ([]*ssh.Host, error) {
+		paths := ssh.ConfigPaths(command.Lookup[string](fs, "ssh-config"), command.Lookup[bool](fs, "only"))
+		return ssh.LoadSSHConfig(paths, opts)
+	}
+
+	if profiles := command.Lookup[stringList](fs, "profile"); len(profiles) > 0 {
+		load = func() ([]*ssh.Host, error) {
+			return loadProfiles(profiles, opts)
+		}
+	}
+
+	descriptions := command.Lookup[string](fs, "descriptions")
+	filter := command.Lookup[string](fs, "filter-cmd")
+
+	return func() ([]*ssh.Host, error) {
+		if diags != nil {
+			diags.list = nil
+		}
+
+		hosts, err := load()
+		if err != nil {
+			return nil, err
+		}
+
+		ssh.ApplyDescriptions(hosts, ssh.LoadDescriptions(descriptions))
+
+		if filter != "" {
+			return filterHosts(filter, hosts)
+		}
+
+		return hosts, nil
+	}
+}
+
+func printHost(w io.Writer, host *ssh.Host) error {
+	if _, err := fmt.Fprintln(w, host.Name); err != nil {
+		return fmt.Errorf("could not write host name: %w", err)
+	}
+
+	return nil
+}
+
+func printCmd(w io.Writer, host *ssh.Host, tmpl string, vars ssh.CmdVars) error {
+	cmd, err := host.RenderCmd(tmpl, vars)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintln(w, cmd); err != nil {
+		return fmt.Errorf("could not write command: %w", err)
+	}
+
+	return nil
+}
+
+// connectOptions controls how runSSH handles the end of a session.
+type connectOptions struct {
+	// reattach offers to reconnect after a session exits cleanly.
+	reattach bool
+	// auditHook is a shell command run after every session, if set.
+	auditHook string
+	// retry controls retrying failed connections.
+	retry retryOptions
+	// runner runs the command for a session, attached to the terminal if nil.
+	runner ssh.Runner
+	// in and out are where the reconnect prompt is read from and written to,
+	// stdin and stdout if nil.
+	in  io.Reader
+	out io.Writer
+	// cliFlags are the flags given on the command line, which override a
+	// host's #pssh: flags.
+	cliFlags map[string]string
+}
+
+// retryOptions controls how runSSH retries a failed connection.
+type retryOptions struct {
+	// delay is how long to wait before each retry.
+	delay time.Duration
+	// max is how many failed attempts to make before giving up, with 0 for no
+	// limit. A host's #retries annotation takes precedence.
+	max int
+	// backoff doubles delay after each failed attempt, up to backoffCap.
+	backoff    bool
+	backoffCap time.Duration
+}
+
+// wait is how long to wait after the given failed attempt.
+func (r retryOptions) wait(attempt int) time.Duration {
+	if !r.backoff {
+		return r.delay
+	}
+
+	return nextDelay(attempt, r.delay, r.backoffCap)
+}
+
+// nextDelay is the delay after the given failed attempt, counting from 1, with
+// exponential backoff: base doubled for each attempt after the first, but never
+// more than limit.
+func nextDelay(attempt int, base, limit time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempt && delay < limit; i++ {
+		delay *= 2
+	}
+
+	return min(delay, limit)
+}
+
+// exhausted reports whether attempt was the last one allowed for host.
+func (r retryOptions) exhausted(host *ssh.Host, attempt int) bool {
+	if host.Retries > 0 {
+		return attempt > host.Retries
+	}
+
+	return r.max > 0 && attempt >= r.max
+}
+
+// confirm asks a yes/no question, defaulting to no.
+func confirm(in io.Reader, out io.Writer, prompt string) bool {
+	_, _ = fmt.Fprint(out, prompt)
+
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && answer == "" {
+		return false
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
+func runSSH(host *ssh.Host, tmpl string, vars ssh.CmdVars, opts connectOptions) error {
+	opts, vars, err := opts.forHost(host, vars)
+	if err != nil {
+		return err
+	}
+
+	// Catch a missing binary (e.g. a typo in the template) before retrying on it.
+	if _, err := host.CmdArgs(tmpl, vars); err != nil {
+		return err
+	}
+
+	// Read answers through one buffer, so none are lost between prompts.
+	in := bufio.NewReader(cmp.Or[io.Reader](opts.in, os.Stdin))
+	out := cmp.Or[io.Writer](opts.out, os.Stdout)
+
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+		err := host.RunCmdTmplWith(opts.runner, tmpl, vars)
+
+		if opts.auditHook != "" {
+			runAuditHook(opts.auditHook, host, vars, exitStatus(err))
+		}
 
-	for {
-		err := host.RunCmdTmpl(tmpl)
 		if err == nil {
 			// log.Info("Connection closed.")
+			recordConnection(host, start, nil)
+
+			if opts.reattach && confirm(in, out, "Reconnect? [y/N] ") {
+				attempt = 0
+				continue
+			}
+
 			break
 		}
 
 		var exitErr *exec.ExitError
 		if errors.As(err, &exitErr) {
+			if opts.retry.exhausted(host, attempt) {
+				recordConnection(host, start, err)
+
+				return fmt.Errorf("giving up on %s after %d failed attempts: %w", host.Name, attempt, err)
+			}
+
 			// This is an expected error from ssh, so we can retry.
-			log.Infof("Connection failed, retrying in 2 seconds. Press Ctrl+C to cancel.")
-			time.Sleep(2 * time.Second)
+			delay := opts.retry.wait(attempt)
+			log.Infof("Connection failed (attempt %d), retrying in %s. Press Ctrl+C to cancel.", attempt, delay)
+			time.Sleep(delay)
 
 			continue
 		}
 
 		// This is an unexpected error, so we should stop.
+		recordConnection(host, start, err)
+
 		return fmt.Errorf("ssh command failed unexpectedly: %w", err)
 	}
 
 	return nil
 }
+
+// exitStatus is the exit status of a session ending with err, or -1 if the
+// command didn't run to an exit.
+func exitStatus(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+
+	return -1
+}
+
+// recordConnection adds a finished session to the history. Failed attempts
+// which are retried aren't sessions, so only the last attempt is recorded.
+// Failing to record it shouldn't stop anything, so errors are only logged.
+func recordConnection(host *ssh.Host, start time.Time, err error) {
+	entry := history.Entry{
+		Host:       host.Name,
+		Time:       start,
+		Duration:   time.Since(start),
+		ExitStatus: exitStatus(err),
+	}
+
+	if err := history.Record(entry); err != nil {
+		log.Warn("could not record connection", "err", err)
+	}
+}
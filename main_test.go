@@ -0,0 +1,396 @@
+package main
+
+import (
+	"flag"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pix-xip/go-command"
+	"github.com/pix-xip/pssh/config"
+	"github.com/pix-xip/pssh/history"
+	"github.com/pix-xip/pssh/ssh"
+)
+
+func TestPrintHost(t *testing.T) {
+	var out strings.Builder
+	if err := printHost(&out, &ssh.Host{Name: "web1", Hostname: "10.0.0.1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := out.String(); got != "web1\n" {
+		t.Errorf("printed %q, want only the host name", got)
+	}
+}
+
+func TestPrintCmd(t *testing.T) {
+	var out strings.Builder
+
+	vars := ssh.CmdVars{ExtraArgs: ssh.Args{"-L", "8080:localhost:80"}}
+	if err := printCmd(&out, &ssh.Host{Name: "web1"}, "ssh {{.ExtraArgs}}  {{.Name}}", vars); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := out.String(), "ssh -L 8080:localhost:80 web1\n"; got != want {
+		t.Errorf("printed %q, want %q", got, want)
+	}
+
+	if err := printCmd(&out, &ssh.Host{Name: "web1"}, "ssh {{.Missing}}", vars); err == nil {
+		t.Error("printCmd with a bad template succeeded")
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"y\n", true},
+		{"Yes\n", true},
+		{" y \n", true},
+		{"y", true},
+		{"n\n", false},
+		{"\n", false},
+		{"yep\n", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		var out strings.Builder
+		if got := confirm(strings.NewReader(tt.input), &out, "Reconnect? "); got != tt.want {
+			t.Errorf("confirm(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+
+		if out.String() != "Reconnect? " {
+			t.Errorf("prompted %q", out.String())
+		}
+	}
+}
+
+func TestRetryExhausted(t *testing.T) {
+	tests := []struct {
+		name    string
+		max     int
+		retries int
+		attempt int
+		want    bool
+	}{
+		{"no limit", 0, 0, 100, false},
+		{"under the limit", 3, 0, 2, false},
+		{"at the limit", 3, 0, 3, true},
+		// #retries counts retries after the first attempt, and wins over --retry-max
+		{"annotation", 3, 5, 5, false},
+		{"annotation used up", 3, 5, 6, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host := &ssh.Host{Name: "web1", Retries: tt.retries}
+
+			if got := (retryOptions{max: tt.max}).exhausted(host, tt.attempt); got != tt.want {
+				t.Errorf("exhausted(%d) = %v, want %v", tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextDelay(t *testing.T) {
+	const base, limit = 2 * time.Second, time.Minute
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 2 * time.Second},
+		{2, 4 * time.Second},
+		{3, 8 * time.Second},
+		{5, 32 * time.Second},
+		{6, time.Minute},
+		{100, time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := nextDelay(tt.attempt, base, limit); got != tt.want {
+			t.Errorf("nextDelay(%d) = %s, want %s", tt.attempt, got, tt.want)
+		}
+	}
+
+	// A base over the cap is capped from the first attempt
+	if got := nextDelay(1, 2*time.Minute, limit); got != limit {
+		t.Errorf("nextDelay(1) with a base over the cap = %s, want %s", got, limit)
+	}
+}
+
+func TestRetryWait(t *testing.T) {
+	fixed := retryOptions{delay: 2 * time.Second, backoffCap: time.Minute}
+	backoff := fixed
+	backoff.backoff = true
+
+	for attempt, want := range map[int]time.Duration{1: 2 * time.Second, 3: 2 * time.Second} {
+		if got := fixed.wait(attempt); got != want {
+			t.Errorf("wait(%d) without backoff = %s, want %s", attempt, got, want)
+		}
+	}
+
+	for attempt, want := range map[int]time.Duration{1: 2 * time.Second, 3: 8 * time.Second} {
+		if got := backoff.wait(attempt); got != want {
+			t.Errorf("wait(%d) with backoff = %s, want %s", attempt, got, want)
+		}
+	}
+}
+
+// fakeRunner returns each of errs in turn, counting the commands run.
+type fakeRunner struct {
+	errs []error
+	runs int
+}
+
+func (r *fakeRunner) Run(string, ...string) error {
+	r.runs++
+
+	err := r.errs[0]
+	if len(r.errs) > 1 {
+		r.errs = r.errs[1:]
+	}
+
+	return err
+}
+
+// exitError returns the error from a command exiting with status.
+func exitError(t *testing.T, status string) error {
+	t.Helper()
+
+	err := exec.Command("sh", "-c", "exit "+status).Run()
+	if err == nil {
+		t.Fatalf("exit %s succeeded", status)
+	}
+
+	return err
+}
+
+func TestRunSSHRetries(t *testing.T) {
+	failed := exitError(t, "255")
+
+	tests := []struct {
+		name     string
+		errs     []error
+		max      int
+		wantRuns int
+		wantErr  bool
+	}{
+		{"connects", []error{nil}, 0, 1, false},
+		{"connects after retrying", []error{failed, failed, nil}, 0, 3, false},
+		{"gives up", []error{failed}, 2, 2, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+			runner := &fakeRunner{errs: tt.errs}
+			opts := connectOptions{runner: runner, retry: retryOptions{max: tt.max}}
+
+			err := runSSH(&ssh.Host{Name: "web1"}, "true {{.Name}}", ssh.CmdVars{}, opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("runSSH = %v, want error %v", err, tt.wantErr)
+			}
+
+			if runner.runs != tt.wantRuns {
+				t.Errorf("ran %d times, want %d", runner.runs, tt.wantRuns)
+			}
+		})
+	}
+}
+
+func TestRunSSHReattach(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantRuns int
+	}{
+		{"yes reconnects", "y\nn\n", 2},
+		{"twice", "y\nyes\n", 3},
+		{"no returns", "n\n", 1},
+		{"EOF returns", "", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+			var out strings.Builder
+
+			runner := &fakeRunner{errs: []error{nil}}
+			opts := connectOptions{reattach: true, runner: runner, in: strings.NewReader(tt.input), out: &out}
+
+			if err := runSSH(&ssh.Host{Name: "web1"}, "true {{.Name}}", ssh.CmdVars{}, opts); err != nil {
+				t.Fatal(err)
+			}
+
+			if runner.runs != tt.wantRuns {
+				t.Errorf("ran %d times, want %d", runner.runs, tt.wantRuns)
+			}
+
+			if got := strings.Count(out.String(), "Reconnect? [y/N] "); got != tt.wantRuns {
+				t.Errorf("asked to reconnect %d times, want %d", got, tt.wantRuns)
+			}
+		})
+	}
+}
+
+func TestRunSSHRecordsOnce(t *testing.T) {
+	failed := exitError(t, "255")
+
+	tests := []struct {
+		name     string
+		errs     []error
+		max      int
+		wantRuns int
+		wantExit int
+	}{
+		{"connects", []error{nil}, 0, 1, 0},
+		{"connects after retrying", []error{failed, failed, nil}, 0, 3, 0},
+		{"gives up", []error{failed}, 3, 3, 255},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+			runner := &fakeRunner{errs: tt.errs}
+			opts := connectOptions{runner: runner, retry: retryOptions{max: tt.max}}
+
+			_ = runSSH(&ssh.Host{Name: "web1"}, "true {{.Name}}", ssh.CmdVars{}, opts)
+
+			if runner.runs != tt.wantRuns {
+				t.Errorf("ran %d times, want %d", runner.runs, tt.wantRuns)
+			}
+
+			entries, err := history.Entries()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(entries) != 1 {
+				t.Fatalf("recorded %d connections, want 1", len(entries))
+			}
+
+			if got := entries[0].ExitStatus; got != tt.wantExit {
+				t.Errorf("recorded exit status %d, want %d", got, tt.wantExit)
+			}
+		})
+	}
+}
+
+func TestRootFlags(t *testing.T) {
+	loop := false
+	cfg := &config.Config{SSHConfig: "~/.ssh/work", Loop: &loop, RetryDelay: 5 * time.Second}
+
+	tests := []struct {
+		name       string
+		args       []string
+		sshConfig  string
+		loop       bool
+		retryDelay time.Duration
+	}{
+		{"config file", nil, "~/.ssh/work", false, 5 * time.Second},
+		{"flags win", []string{"--ssh-config", "/tmp/config", "--loop", "--retry-delay", "1s"}, "/tmp/config", true, time.Second},
+		{"flag and config file", []string{"--loop=true"}, "~/.ssh/work", true, 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("pssh", flag.ContinueOnError)
+			rootFlags(cfg)(fs)
+
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := command.Lookup[string](fs, "ssh-config"); got != tt.sshConfig {
+				t.Errorf("ssh-config = %q, want %q", got, tt.sshConfig)
+			}
+
+			if got := command.Lookup[bool](fs, "loop"); got != tt.loop {
+				t.Errorf("loop = %v, want %v", got, tt.loop)
+			}
+
+			if got := command.Lookup[time.Duration](fs, "retry-delay"); got != tt.retryDelay {
+				t.Errorf("retry-delay = %v, want %v", got, tt.retryDelay)
+			}
+		})
+	}
+
+	// Without a config file the built-in defaults apply
+	fs := flag.NewFlagSet("pssh", flag.ContinueOnError)
+	rootFlags(&config.Config{})(fs)
+
+	if command.Lookup[string](fs, "ssh-config") != defaultSSHConfig || !command.Lookup[bool](fs, "loop") || command.Lookup[time.Duration](fs, "retry-delay") != defaultRetryDelay {
+		t.Errorf("defaults without a config file: ssh-config %q, loop %v, retry-delay %v",
+			command.Lookup[string](fs, "ssh-config"), command.Lookup[bool](fs, "loop"), command.Lookup[time.Duration](fs, "retry-delay"))
+	}
+}
+
+func TestConnectTmpl(t *testing.T) {
+	const configTmpl = "mosh {{.Name}}"
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"config template", nil, configTmpl},
+		{"remote tmux over the config template", []string{"--remote-tmux"}, remoteTmuxTmpl},
+		{"given template over remote tmux", []string{"--remote-tmux", "--connect-template", "ssh {{.Name}}"}, "ssh {{.Name}}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// As registered by main with connect_template set in config.toml
+			fs := flag.NewFlagSet("pssh", flag.ContinueOnError)
+			fs.String("connect-template", configTmpl, "")
+			fs.Bool("remote-tmux", false, "")
+
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := connectTmpl(fs); got != tt.want {
+				t.Errorf("connectTmpl = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoteTmuxTmpl(t *testing.T) {
+	host := &ssh.Host{Name: "web1"}
+
+	tests := []struct {
+		name string
+		vars ssh.CmdVars
+		want string
+	}{
+		{"attach", ssh.CmdVars{}, "ssh -t web1 'tmux attach || tmux new'"},
+		{
+			"identity and jump",
+			ssh.CmdVars{Identity: "/keys/id_ed25519", Jump: "bastion"},
+			"ssh -t -J bastion -i /keys/id_ed25519 web1 'tmux attach || tmux new'",
+		},
+		{"command", ssh.CmdVars{Command: "uptime; df -h"}, `ssh -t web1 'tmux new '\''uptime; df -h'\'''`},
+		{"single word command", ssh.CmdVars{Command: "htop"}, "ssh -t web1 'tmux new htop'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := host.RenderCmd(remoteTmuxTmpl, tt.vars)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tt.want {
+				t.Errorf("RenderCmd = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pix-xip/go-command"
+	"github.com/pix-xip/pssh/ssh"
+)
+
+// stringList is a flag which can be given multiple times.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+func (l *stringList) Get() any { return *l }
+
+// profilePath returns the ssh config file for a named profile, kept in
+// $XDG_CONFIG_HOME/pssh/profiles/<name>.
+func profilePath(name string) (string, error) {
+	if name == "" || strings.ContainsRune(name, filepath.Separator) {
+		return "", fmt.Errorf("invalid profile name %q", name)
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("could not get user config directory: %w", err)
+	}
+
+	return filepath.Join(dir, "pssh", "profiles", name), nil
+}
+
+// primaryConfig returns the ssh config file opened by ctrl+o: the first
+// profile's when loading from --profile, or else --ssh-config.
+func primaryConfig(fs *flag.FlagSet) string {
+	if profiles := command.Lookup[stringList](fs, "profile"); len(profiles) > 0 {
+		if fp, err := profilePath(profiles[0]); err == nil {
+			return fp
+		}
+	}
+
+	return command.Lookup[string](fs, "ssh-config")
+}
+
+// loadProfiles loads and merges the hosts of each profile, labelling every
+// host with the profile it came from.
+func loadProfiles(names []string, opts ssh.LoadOptions) ([]*ssh.Host, error) {
+	var hosts []*ssh.Host
+
+	for _, name := range names {
+		fp, err := profilePath(name)
+		if err != nil {
+			return nil, err
+		}
+
+		profileHosts, err := ssh.LoadSSHConfig([]string{fp}, opts)
+		if err != nil {
+			return nil, fmt.Errorf("could not load profile %s: %w", name, err)
+		}
+
+		for _, h := range profileHosts {
+			h.Profile = name
+		}
+
+		hosts = append(hosts, profileHosts...)
+	}
+
+	return hosts, nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/pix-xip/pssh/ssh"
+)
+
+func TestLoadProfiles(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	profiles := map[string]string{
+		"work": "Host build1\n  HostName 10.0.0.1\n",
+		"home": "Host nas\n  HostName 192.168.1.2\n",
+	}
+
+	for name, config := range profiles {
+		path := filepath.Join(dir, "pssh", "profiles", name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	hosts, err := loadProfiles([]string{"work", "home"}, ssh.LoadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, h := range hosts {
+		got = append(got, h.Profile+"/"+h.Name)
+	}
+
+	if want := []string{"work/build1", "home/nas"}; !slices.Equal(got, want) {
+		t.Errorf("loaded %q, want %q", got, want)
+	}
+
+	// A missing profile is skipped with a warning, like a missing config
+	var warnings []string
+
+	hosts, err = loadProfiles([]string{"missing"}, ssh.LoadOptions{Warn: func(d ssh.Diagnostic) { warnings = append(warnings, d.Message) }})
+	if err != nil || len(hosts) != 0 || len(warnings) != 1 {
+		t.Errorf("loading a missing profile: %d hosts, %q, %v", len(hosts), warnings, err)
+	}
+}
+
+func TestProfilePath(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/config")
+
+	got, err := profilePath("work")
+	if err != nil || got != "/config/pssh/profiles/work" {
+		t.Errorf("profilePath(work) = %q, %v", got, err)
+	}
+
+	for _, name := range []string{"", "../work", "a/b"} {
+		if _, err := profilePath(name); err == nil {
+			t.Errorf("profilePath(%q) succeeded, want an error", name)
+		}
+	}
+}
+
+func TestPrimaryConfig(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/config")
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"ssh config", nil, "/home/me/.ssh/config"},
+		{"given ssh config", []string{"--ssh-config", "/tmp/config"}, "/tmp/config"},
+		{"profile", []string{"--profile", "work"}, "/config/pssh/profiles/work"},
+		{"first profile", []string{"--profile", "work", "--profile", "home"}, "/config/pssh/profiles/work"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("pssh", flag.ContinueOnError)
+			fs.String("ssh-config", "/home/me/.ssh/config", "")
+			fs.Var(&stringList{}, "profile", "")
+
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := primaryConfig(fs); got != tt.want {
+				t.Errorf("primaryConfig = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
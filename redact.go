@@ -0,0 +1,77 @@
+package main
+
+import (
+	"maps"
+
+	"github.com/pix-xip/pssh/ssh"
+)
+
+// redact masks a value, keeping only enough of the start to tell values
+// apart, e.g. "web01.example.com" becomes "web***" and "ubuntu" "u***".
+func redact(s string) string {
+	if s == "" {
+		return ""
+	}
+
+	r := []rune(s)
+	keep := min(max(len(r)/4, 1), 3)
+
+	return string(r[:keep]) + "***"
+}
+
+func redactAll(ss []string) []string {
+	if ss == nil {
+		return nil
+	}
+
+	out := make([]string, len(ss))
+	for i, s := range ss {
+		out[i] = redact(s)
+	}
+
+	return out
+}
+
+// redactHost returns a copy of h with anything naming a machine or person
+// masked, for sharing output without giving away the config.
+func redactHost(h *ssh.Host) *ssh.Host {
+	r := *h
+
+	r.Name = redact(h.Name)
+	r.Aliases = redactAll(h.Aliases)
+	r.User = redact(h.User)
+	r.Hostname = redact(h.Hostname)
+	r.CanonicalHostname = redact(h.CanonicalHostname)
+	r.ProxyCommand = redact(h.ProxyCommand)
+	r.Forwards = redactAll(h.Forwards)
+	r.IdentityFile = redact(h.IdentityFile)
+	r.IdentityFiles = redactAll(h.IdentityFiles)
+	r.Description = redact(h.Description)
+	r.SourceFile = redact(h.SourceFile)
+
+	opts := maps.Clone(h.OtherOptions())
+	for k, v := range opts {
+		opts[k] = redact(v)
+	}
+
+	r.SetOtherOptions(opts)
+
+	if h.SetEnv != nil {
+		r.SetEnv = maps.Clone(h.SetEnv)
+		for k, v := range r.SetEnv {
+			r.SetEnv[k] = redact(v)
+		}
+	}
+
+	return &r
+}
+
+// redactHosts returns redacted copies of hosts.
+func redactHosts(hosts []*ssh.Host) []*ssh.Host {
+	out := make([]*ssh.Host, len(hosts))
+	for i, h := range hosts {
+		out[i] = redactHost(h)
+	}
+
+	return out
+}
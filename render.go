@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/pix-xip/go-command"
+	"github.com/pix-xip/pssh/ssh"
+)
+
+// RunRender prints the command a template renders to for a host, to help
+// write templates without connecting anywhere.
+func RunRender(_ context.Context, fs *flag.FlagSet, args []string) error {
+	name := command.Lookup[string](fs, "host")
+	if name == "" {
+		return errors.New("--host is required")
+	}
+
+	hosts, err := hostLoader(fs, nil)()
+	if err != nil {
+		return err
+	}
+
+	host := findHost(hosts, name)
+	if host == nil {
+		return fmt.Errorf("host %q not found in ssh config", name)
+	}
+
+	vars := ssh.CmdVars{
+		ExtraArgs: args,
+		Jump:      ssh.JumpHost(command.Lookup[string](fs, "jump")),
+		Command:   ssh.RemoteCommand(command.Lookup[string](fs, "command")),
+	}
+
+	return printCmd(os.Stdout, host, command.Lookup[string](fs, "template"), vars)
+}
+
+// findHost returns the first host with the given name or alias, or nil.
+func findHost(hosts []*ssh.Host, name string) *ssh.Host {
+	if found := findHosts(hosts, name); len(found) > 0 {
+		return found[0]
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"testing"
+
+	"github.com/pix-xip/pssh/ssh"
+)
+
+func TestFindHost(t *testing.T) {
+	hosts := []*ssh.Host{
+		{Name: "web1", Aliases: []string{"www"}},
+		{Name: "db1"},
+	}
+
+	for name, want := range map[string]*ssh.Host{
+		"web1":  hosts[0],
+		"www":   hosts[0],
+		"db1":   hosts[1],
+		"cache": nil,
+		"web":   nil,
+	} {
+		if got := findHost(hosts, name); got != want {
+			t.Errorf("findHost(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestRunRenderNeedsHost(t *testing.T) {
+	fs := flag.NewFlagSet("render", flag.ContinueOnError)
+	fs.String("host", "", "")
+
+	if err := RunRender(context.Background(), fs, nil); err == nil || err.Error() != "--host is required" {
+		t.Errorf("RunRender without --host = %v, want an error", err)
+	}
+}
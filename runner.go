@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+
+	"github.com/pix-xip/pssh/ssh"
+)
+
+// maxParallel caps how many hosts runAll runs the command on at once.
+const maxParallel = 8
+
+// runAll runs the command template on every host in parallel, writing their
+// output to w with each line prefixed by the host name.
+func runAll(w io.Writer, hosts []*ssh.Host, tmpl string, vars ssh.CmdVars) error {
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		errs = make([]error, len(hosts))
+		sem  = make(chan struct{}, maxParallel)
+	)
+
+	for i, host := range hosts {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			out := &prefixWriter{w: w, mu: &mu, prefix: "[" + host.Name + "] "}
+			defer out.Flush()
+
+			errs[i] = runOn(host, tmpl, vars, out)
+		}()
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// runOn runs the command template for host without a terminal.
+func runOn(host *ssh.Host, tmpl string, vars ssh.CmdVars, out io.Writer) error {
+	parts, err := host.CmdArgs(tmpl, vars)
+	if err != nil {
+		return fmt.Errorf("%s: %w", host.Name, err)
+	}
+
+	cmd := exec.Command(parts[0], parts[1:]...)
+	cmd.Stdout = out
+	cmd.Stderr = out
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w", host.Name, err)
+	}
+
+	return nil
+}
+
+// prefixWriter writes whole lines to w with a prefix, so output from several
+// hosts can share w without lines interleaving.
+type prefixWriter struct {
+	w      io.Writer
+	mu     *sync.Mutex
+	prefix string
+	buf    []byte
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			return len(b), nil
+		}
+
+		if err := p.writeLine(p.buf[:i+1]); err != nil {
+			return 0, err
+		}
+
+		p.buf = p.buf[i+1:]
+	}
+}
+
+// Flush writes any final line without a newline.
+func (p *prefixWriter) Flush() {
+	if len(p.buf) > 0 {
+		_ = p.writeLine(append(p.buf, '\n'))
+		p.buf = nil
+	}
+}
+
+func (p *prefixWriter) writeLine(line []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, err := fmt.Fprintf(p.w, "%s%s", p.prefix, line)
+
+	return err
+}
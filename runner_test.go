@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pix-xip/pssh/ssh"
+)
+
+func TestRunAll(t *testing.T) {
+	hosts := []*ssh.Host{{Name: "web1"}, {Name: "web2"}}
+
+	var out strings.Builder
+	if err := runAll(&out, hosts, "echo hello {{.Name}}", ssh.CmdVars{}); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	slices.Sort(lines)
+
+	if want := []string{"[web1] hello web1", "[web2] hello web2"}; !slices.Equal(lines, want) {
+		t.Errorf("output = %q, want %q", lines, want)
+	}
+
+	if err := runAll(&out, hosts, "false {{.Name}}", ssh.CmdVars{}); err == nil {
+		t.Error("no error when the command failed")
+	}
+}
+
+func TestPrefixWriter(t *testing.T) {
+	var (
+		out strings.Builder
+		mu  sync.Mutex
+	)
+
+	p := &prefixWriter{w: &out, mu: &mu, prefix: "[web1] "}
+	fmt.Fprint(p, "one\ntw")
+	fmt.Fprint(p, "o\nthree")
+
+	if got, want := out.String(), "[web1] one\n[web1] two\n"; got != want {
+		t.Errorf("before Flush = %q, want %q", got, want)
+	}
+
+	p.Flush()
+
+	if got, want := out.String(), "[web1] one\n[web1] two\n[web1] three\n"; got != want {
+		t.Errorf("after Flush = %q, want %q", got, want)
+	}
+}
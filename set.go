@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/pix-xip/go-command"
+	"github.com/pix-xip/pssh/ssh"
+)
+
+// RunSet writes an option into the config block of every host given with
+// --host, e.g. pssh set --host web1 --host web2 ForwardAgent no
+func RunSet(_ context.Context, fs *flag.FlagSet, args []string) error {
+	if command.Lookup[bool](fs, "read-only") {
+		return errors.New("cannot change the ssh config in read-only mode")
+	}
+
+	if len(args) != 2 {
+		return errors.New("usage: pssh set --host NAME [--host NAME...] KEY VALUE")
+	}
+
+	names := command.Lookup[stringList](fs, "host")
+	if len(names) == 0 {
+		return errors.New("--host is required")
+	}
+
+	hosts, err := hostLoader(fs, nil)()
+	if err != nil {
+		return err
+	}
+
+	selected := make([]*ssh.Host, 0, len(names))
+	for _, name := range names {
+		host := findHost(hosts, name)
+		if host == nil {
+			return fmt.Errorf("host %q not found in ssh config", name)
+		}
+
+		selected = append(selected, host)
+	}
+
+	return ssh.SetOptionAll(selected, args[0], args[1])
+}
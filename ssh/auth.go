@@ -0,0 +1,82 @@
+package ssh
+
+import (
+	"os"
+	"strings"
+)
+
+// AuthMethod is how a host is expected to authenticate.
+type AuthMethod int
+
+const (
+	// AuthKey is a key, from an IdentityFile or the agent.
+	AuthKey AuthMethod = iota
+	// AuthPassword is a password or other interactive prompt.
+	AuthPassword
+)
+
+// Badge is a compact marker for the method, for a table column.
+func (a AuthMethod) Badge() string {
+	if a == AuthPassword {
+		return "🔒"
+	}
+
+	return "🔑"
+}
+
+func (a AuthMethod) String() string {
+	if a == AuthPassword {
+		return "password"
+	}
+
+	return "key"
+}
+
+// Auth guesses how ssh will authenticate to h from its options. It's a
+// heuristic: whether a key works is only known by trying.
+func (h *Host) Auth() AuthMethod {
+	return authMethod(h.Resolve(), os.Getenv("SSH_AUTH_SOCK") != "")
+}
+
+// authMethod guesses the authentication for opts, with agent reporting
+// whether an ssh agent is running.
+func authMethod(opts []Option, agent bool) AuthMethod {
+	values := make(map[string]string, len(opts))
+	for _, o := range opts {
+		key := strings.ToLower(o.Key)
+		if _, ok := values[key]; !ok {
+			values[key] = strings.ToLower(o.Value)
+		}
+	}
+
+	if values["pubkeyauthentication"] == "no" {
+		return AuthPassword
+	}
+
+	if preferred, ok := values["preferredauthentications"]; ok {
+		first, _, _ := strings.Cut(preferred, ",")
+		if first == "password" || first == "keyboard-interactive" {
+			return AuthPassword
+		}
+	}
+
+	for _, key := range []string{"identityfile", "certificatefile", "pkcs11provider"} {
+		if v, ok := values[key]; ok && v != "none" {
+			return AuthKey
+		}
+	}
+
+	if v, ok := values["identityagent"]; ok {
+		if v == "none" {
+			return AuthPassword
+		}
+
+		return AuthKey
+	}
+
+	if values["passwordauthentication"] == "no" || agent {
+		return AuthKey
+	}
+
+	return AuthPassword
+}
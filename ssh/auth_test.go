@@ -0,0 +1,37 @@
+package ssh
+
+import (
+	"testing"
+)
+
+func TestAuthMethod(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  []Option
+		agent bool
+		want  AuthMethod
+	}{
+		{"nothing set", nil, false, AuthPassword},
+		{"agent running", nil, true, AuthKey},
+		{"identity file", []Option{{"IdentityFile", "~/.ssh/web"}}, false, AuthKey},
+		{"identity file none", []Option{{"IdentityFile", "none"}}, false, AuthPassword},
+		{"certificate", []Option{{"CertificateFile", "~/.ssh/web-cert.pub"}}, false, AuthKey},
+		{"agent socket", []Option{{"IdentityAgent", "~/.1password/agent.sock"}}, false, AuthKey},
+		{"agent disabled", []Option{{"IdentityAgent", "none"}}, true, AuthPassword},
+		{"no passwords", []Option{{"PasswordAuthentication", "no"}}, false, AuthKey},
+		{"no keys", []Option{{"PubkeyAuthentication", "no"}, {"IdentityFile", "~/.ssh/web"}}, true, AuthPassword},
+		{"password preferred", []Option{{"PreferredAuthentications", "password,publickey"}}, true, AuthPassword},
+		{"keyboard-interactive preferred", []Option{{"PreferredAuthentications", "keyboard-interactive"}}, true, AuthPassword},
+		{"key preferred", []Option{{"PreferredAuthentications", "publickey,password"}, {"IdentityFile", "~/.ssh/web"}}, false, AuthKey},
+		// The first value of an option wins, as in ssh
+		{"first value", []Option{{"PubkeyAuthentication", "yes"}, {"PubkeyAuthentication", "no"}}, true, AuthKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := authMethod(tt.opts, tt.agent); got != tt.want {
+				t.Errorf("authMethod = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
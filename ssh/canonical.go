@@ -0,0 +1,58 @@
+package ssh
+
+import (
+	"net"
+	"strconv"
+	"strings"
+)
+
+// defaultCanonicalMaxDots is ssh's default for CanonicalizeMaxDots.
+const defaultCanonicalMaxDots = 1
+
+// canonicalHostname returns the name ssh would connect to h with after
+// CanonicalizeHostname, or "" if it isn't canonicalized. ssh looks the name up
+// under each of CanonicalDomains in turn; without resolving anything, this
+// takes the first domain as a best guess.
+//
+// As in ssh, only hosts without a Hostname option are canonicalized, since an
+// explicit Hostname replaces the canonical name.
+func (h *Host) canonicalHostname() string {
+	blocks := h.blocks()
+
+	mode := strings.ToLower(resolveEffective(blocks, h.Name, "canonicalizehostname"))
+	if mode != "yes" && mode != "always" {
+		return ""
+	}
+
+	// With "yes", proxied connections are left alone
+	if mode == "yes" && h.IsProxied() {
+		return ""
+	}
+
+	if resolveEffective(blocks, h.Name, "hostname") != "" || net.ParseIP(h.Name) != nil {
+		return ""
+	}
+
+	// A trailing dot marks the name as already canonical
+	if name, ok := strings.CutSuffix(h.Name, "."); ok {
+		return name
+	}
+
+	maxDots := defaultCanonicalMaxDots
+	if v := resolveEffective(blocks, h.Name, "canonicalizemaxdots"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			maxDots = n
+		}
+	}
+
+	if strings.Count(h.Name, ".") > maxDots {
+		return ""
+	}
+
+	domains := strings.Fields(resolveEffective(blocks, h.Name, "canonicaldomains"))
+	if len(domains) == 0 {
+		return ""
+	}
+
+	return h.Name + "." + strings.TrimSuffix(domains[0], ".")
+}
@@ -0,0 +1,70 @@
+package ssh
+
+import (
+	"testing"
+)
+
+func TestCanonicalHostname(t *testing.T) {
+	tests := []struct {
+		name, config, host, want string
+	}{
+		{
+			"first domain",
+			"Host web1\nHost *\n  CanonicalizeHostname yes\n  CanonicalDomains example.com example.org\n",
+			"web1", "web1.example.com",
+		},
+		{
+			"no matching block",
+			"Host web1\nHost db*\n  CanonicalizeHostname yes\n  CanonicalDomains example.com\n",
+			"web1", "",
+		},
+		{
+			"no domains",
+			"Host web1\n  CanonicalizeHostname yes\n",
+			"web1", "",
+		},
+		{
+			"explicit hostname",
+			"Host web1\n  HostName 10.0.0.1\nHost *\n  CanonicalizeHostname yes\n  CanonicalDomains example.com\n",
+			"web1", "",
+		},
+		{
+			"proxied with yes",
+			"Host web1\n  ProxyJump bastion\nHost *\n  CanonicalizeHostname yes\n  CanonicalDomains example.com\n",
+			"web1", "",
+		},
+		{
+			"proxied with always",
+			"Host web1\n  ProxyJump bastion\nHost *\n  CanonicalizeHostname always\n  CanonicalDomains example.com\n",
+			"web1", "web1.example.com",
+		},
+		{
+			"too many dots",
+			"Host web1.eu.prod\nHost *\n  CanonicalizeHostname yes\n  CanonicalDomains example.com\n",
+			"web1.eu.prod", "",
+		},
+		{
+			"trailing dot",
+			"Host web1.example.net.\nHost *\n  CanonicalizeHostname yes\n  CanonicalDomains example.com\n",
+			"web1.example.net.", "web1.example.net",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, h := range loadConfig(t, tt.config) {
+				if h.Name != tt.host {
+					continue
+				}
+
+				if h.CanonicalHostname != tt.want {
+					t.Errorf("CanonicalHostname = %q, want %q", h.CanonicalHostname, tt.want)
+				}
+
+				return
+			}
+
+			t.Fatalf("no host %s", tt.host)
+		})
+	}
+}
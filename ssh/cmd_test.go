@@ -0,0 +1,308 @@
+package ssh
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestSplitCmdExtraArgs(t *testing.T) {
+	host := &Host{Name: "web1"}
+
+	tests := []struct {
+		name  string
+		extra Args
+		want  []string
+	}{
+		{"none", nil, []string{"ssh", "web1"}},
+		{"options", Args{"-v", "-L", "8080:localhost:80"}, []string{"ssh", "-v", "-L", "8080:localhost:80", "web1"}},
+		{
+			"spaces and quotes",
+			Args{"-o", "ProxyCommand=ssh bastion nc %h %p", "-o", `RemoteCommand=echo "it's"`},
+			[]string{"ssh", "-o", "ProxyCommand=ssh bastion nc %h %p", "-o", `RemoteCommand=echo "it's"`, "web1"},
+		},
+		{"empty", Args{"-o", ""}, []string{"ssh", "-o", "", "web1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := host.splitCmd("ssh {{.ExtraArgs}} {{.Name}}", CmdVars{ExtraArgs: tt.extra})
+			if err != nil {
+				t.Fatalf("splitCmd: %v", err)
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("splitCmd = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTmplFuncs(t *testing.T) {
+	tests := []struct {
+		name string
+		tmpl string
+		host Host
+		want string
+	}{
+		{"default unset", `ssh {{.User | default "root"}}@{{.Name}}`, Host{Name: "web1"}, "ssh root@web1"},
+		{"default set", `ssh {{.User | default "root"}}@{{.Name}}`, Host{Name: "web1", User: "alice"}, "ssh alice@web1"},
+		{"default nil", `ssh {{.ExtraArgs | default "-v"}} {{.Name}}`, Host{Name: "web1"}, "ssh -v web1"},
+		{"trimSuffix", `ssh {{.Name | trimSuffix ".example.com"}}`, Host{Name: "web1.example.com"}, "ssh web1"},
+		{"trimSuffix no match", `ssh {{.Name | trimSuffix ".example.com"}}`, Host{Name: "web1.test"}, "ssh web1.test"},
+		{"trimPrefix", `ssh {{.Name | trimPrefix "prod-"}}`, Host{Name: "prod-web1"}, "ssh web1"},
+		{"upper and lower", `echo {{.Name | upper}} {{.User | lower}}`, Host{Name: "web1", User: "Alice"}, "echo WEB1 alice"},
+		{"replace", `echo {{.Name | replace "-" "_"}}`, Host{Name: "prod-web-1"}, "echo prod_web_1"},
+		{"quote", `sh -c {{printf "echo %s" .Name | quote}}`, Host{Name: "web1"}, "sh -c 'echo web1'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.host.RenderCmd(tt.tmpl, CmdVars{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tt.want {
+				t.Errorf("RenderCmd(%s) = %q, want %q", tt.tmpl, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIdentity(t *testing.T) {
+	for id, want := range map[Identity]string{
+		"":                 "",
+		"~/.ssh/id_work":   "-i '~/.ssh/id_work'",
+		"/keys/id_ed25519": "-i /keys/id_ed25519",
+		"/keys/my key":     "-i '/keys/my key'",
+	} {
+		if got := id.String(); got != want {
+			t.Errorf("Identity(%q) = %q, want %q", string(id), got, want)
+		}
+	}
+}
+
+func TestRemoteCommand(t *testing.T) {
+	h := &Host{Name: "web1"}
+
+	got, err := h.CmdArgs("ssh {{.Name}} {{.Command}}", CmdVars{Command: "uptime; df -h"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"ssh", "web1", "uptime; df -h"}; !slices.Equal(got, want) {
+		t.Errorf("CmdArgs = %q, want %q", got, want)
+	}
+
+	if got := RemoteCommand("").String(); got != "" {
+		t.Errorf("unset command renders %q, want nothing", got)
+	}
+}
+
+const testTmpl = "ssh {{.Jump}} {{.Override}} {{.Identity}} {{.ExtraArgs}} {{.Name}} {{.Command}}"
+
+func TestRenderCmdCommand(t *testing.T) {
+	host := &Host{Name: "web1"}
+
+	tests := []struct {
+		name    string
+		command RemoteCommand
+		want    string
+		args    []string
+	}{
+		{"no command", "", "ssh web1", []string{"ssh", "web1"}},
+		{"single word", "uptime", "ssh web1 uptime", []string{"ssh", "web1", "uptime"}},
+		{"shell syntax", "uptime; df -h", "ssh web1 'uptime; df -h'", []string{"ssh", "web1", "uptime; df -h"}},
+		{"single quote", "echo 'hi'", `ssh web1 'echo '\''hi'\'''`, []string{"ssh", "web1", "echo 'hi'"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vars := CmdVars{Command: tt.command}
+
+			got, err := host.RenderCmd(testTmpl, vars)
+			if err != nil {
+				t.Fatalf("RenderCmd: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("RenderCmd = %q, want %q", got, tt.want)
+			}
+
+			args, err := host.splitCmd(testTmpl, vars)
+			if err != nil {
+				t.Fatalf("splitCmd: %v", err)
+			}
+
+			if !slices.Equal(args, tt.args) {
+				t.Errorf("splitCmd = %q, want %q", args, tt.args)
+			}
+		})
+	}
+}
+
+func TestConnectTemplates(t *testing.T) {
+	host := &Host{Name: "web1", Hostname: "10.0.0.1", Port: "2222", User: "deploy"}
+
+	tests := []struct {
+		tmpl string
+		want []string
+	}{
+		{"mosh {{.Name}}", []string{"mosh", "web1"}},
+		{"mosh --ssh='ssh -p {{.Port}}' {{.User}}@{{.Hostname}}", []string{"mosh", "--ssh=ssh -p 2222", "deploy@10.0.0.1"}},
+		{"nc {{.Hostname}}:{{.Port}}", []string{"nc", "10.0.0.1:2222"}},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateCmdTmpl(tt.tmpl); err != nil {
+			t.Errorf("ValidateCmdTmpl(%q): %v", tt.tmpl, err)
+			continue
+		}
+
+		got, err := host.splitCmd(tt.tmpl, CmdVars{})
+		if err != nil {
+			t.Fatalf("splitCmd(%q): %v", tt.tmpl, err)
+		}
+
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("splitCmd(%q) = %q, want %q", tt.tmpl, got, tt.want)
+		}
+	}
+
+	if err := ValidateCmdTmpl("mosh {{.Name"); err == nil {
+		t.Error("ValidateCmdTmpl accepted an unclosed action")
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []string
+		wantErr bool
+	}{
+		{"fields", "ssh  host\t-v", []string{"ssh", "host", "-v"}, false},
+		{"empty", "   ", nil, false},
+		{
+			"single quoted proxy command",
+			"ssh host -o 'ProxyCommand=ssh bastion nc %h %p'",
+			[]string{"ssh", "host", "-o", "ProxyCommand=ssh bastion nc %h %p"},
+			false,
+		},
+		{"double quotes", `ssh host "uptime; df -h"`, []string{"ssh", "host", "uptime; df -h"}, false},
+		{"escaped space", `ssh -i my\ key host`, []string{"ssh", "-i", "my key", "host"}, false},
+		{"escape in double quotes", `echo "a\"b\n"`, []string{"echo", `a"b\n`}, false},
+		{"quotes join words", `-o'User=bob'"@"x`, []string{"-oUser=bob@x"}, false},
+		{"empty quotes", `ssh '' host`, []string{"ssh", "", "host"}, false},
+		{"unterminated single quote", "ssh 'host", nil, true},
+		{"unterminated double quote", `ssh "host`, nil, true},
+		{"trailing backslash", `ssh host\`, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := splitArgs(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("splitArgs(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("splitArgs(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArgsRoundTrip(t *testing.T) {
+	args := Args{"ssh", "-o", "ProxyCommand=ssh bastion nc %h %p", "it's", "", "$HOME"}
+
+	got, err := splitArgs(args.String())
+	if err != nil {
+		t.Fatalf("splitArgs: %v", err)
+	}
+
+	if !slices.Equal(got, args) {
+		t.Errorf("splitArgs(%q) = %q, want %q", args.String(), got, []string(args))
+	}
+}
+
+func TestSplitCmdNoHTMLEscaping(t *testing.T) {
+	host := &Host{Name: "ab", User: "bob", Hostname: "a&b.example.com", ProxyCommand: `nc "<%h>" %p`}
+
+	tests := []struct {
+		name string
+		tmpl string
+		want []string
+	}{
+		{"field", "ssh {{.User}}@{{.Hostname}}", []string{"ssh", "bob@a&b.example.com"}},
+		{"helper", "ssh {{.Hostname | upper}}", []string{"ssh", "A&B.EXAMPLE.COM"}},
+		{"quote helper", "ssh -o ProxyCommand={{.ProxyCommand | quote}} {{.Name}}", []string{"ssh", "-o", `ProxyCommand=nc "<%h>" %p`, "ab"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := host.splitCmd(tt.tmpl, CmdVars{})
+			if err != nil {
+				t.Fatalf("splitCmd: %v", err)
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("splitCmd(%q) = %q, want %q", tt.tmpl, got, tt.want)
+			}
+		})
+	}
+}
+
+// recordRunner records the command it's asked to run, returning err.
+type recordRunner struct {
+	argv []string
+	err  error
+}
+
+func (r *recordRunner) Run(name string, args ...string) error {
+	r.argv = append([]string{name}, args...)
+
+	return r.err
+}
+
+func TestRunCmdTmplWith(t *testing.T) {
+	host := &Host{Name: "web1"}
+	failed := errors.New("connection refused")
+
+	tests := []struct {
+		name    string
+		tmpl    string
+		vars    CmdVars
+		err     error
+		want    []string
+		wantErr error
+	}{
+		{"default", "true {{.Name}}", CmdVars{}, nil, []string{"true", "web1"}, nil},
+		{
+			"quoted arguments",
+			"true {{.ExtraArgs}} {{.Name}} {{.Command}}",
+			CmdVars{ExtraArgs: Args{"-o", "ProxyCommand=nc %h %p"}, Command: "uptime; df -h"},
+			nil,
+			[]string{"true", "-o", "ProxyCommand=nc %h %p", "web1", "uptime; df -h"},
+			nil,
+		},
+		{"runner error", "true {{.Name}}", CmdVars{}, failed, []string{"true", "web1"}, failed},
+		// The binary is checked before anything is run
+		{"missing binary", "pssh-no-such-binary {{.Name}}", CmdVars{}, nil, nil, ErrCommandNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &recordRunner{err: tt.err}
+
+			if err := host.RunCmdTmplWith(r, tt.tmpl, tt.vars); !errors.Is(err, tt.wantErr) {
+				t.Errorf("RunCmdTmplWith error = %v, want %v", err, tt.wantErr)
+			}
+
+			if !slices.Equal(r.argv, tt.want) {
+				t.Errorf("ran %q, want %q", r.argv, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package ssh
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// LoadDescriptions reads a sidecar file of host descriptions, one
+// `name description...` pair per line. Blank lines and lines starting with #
+// are skipped. A missing or unreadable file gives no descriptions.
+func LoadDescriptions(path string) map[string]string {
+	descs := map[string]string{}
+
+	f, err := os.Open(ExpandHome(path))
+	if err != nil {
+		return descs
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, desc := line, ""
+		if i := strings.IndexAny(line, " \t"); i >= 0 {
+			name, desc = line[:i], strings.TrimSpace(line[i+1:])
+		}
+
+		descs[name] = desc
+	}
+
+	return descs
+}
+
+// ApplyDescriptions sets each host's description from descs, looked up by
+// name and then by alias.
+func ApplyDescriptions(hosts []*Host, descs map[string]string) {
+	for _, h := range hosts {
+		for _, name := range append([]string{h.Name}, h.Aliases...) {
+			if desc, ok := descs[name]; ok {
+				h.Description = desc
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package ssh
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDescriptions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "descriptions")
+
+	const file = `# name description
+web1    Public web server
+db      Primary database
+
+bare
+`
+
+	if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	descs := LoadDescriptions(path)
+
+	want := map[string]string{"web1": "Public web server", "db": "Primary database", "bare": ""}
+	if !maps.Equal(descs, want) {
+		t.Fatalf("LoadDescriptions = %q, want %q", descs, want)
+	}
+
+	hosts := []*Host{{Name: "web1"}, {Name: "db1", Aliases: []string{"db"}}, {Name: "cache1"}}
+	ApplyDescriptions(hosts, descs)
+
+	for i, want := range []string{"Public web server", "Primary database", ""} {
+		if hosts[i].Description != want {
+			t.Errorf("%s: Description = %q, want %q", hosts[i].Name, hosts[i].Description, want)
+		}
+	}
+
+	if descs := LoadDescriptions(filepath.Join(t.TempDir(), "missing")); len(descs) != 0 {
+		t.Errorf("LoadDescriptions(missing) = %q, want none", descs)
+	}
+}
@@ -0,0 +1,19 @@
+package ssh
+
+import "fmt"
+
+// Diagnostic is a problem found while loading config which doesn't stop the
+// hosts from loading.
+type Diagnostic struct {
+	File    string
+	Line    int
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	if d.Line == 0 {
+		return fmt.Sprintf("%s: %s", d.File, d.Message)
+	}
+
+	return fmt.Sprintf("%s:%d: %s", d.File, d.Line, d.Message)
+}
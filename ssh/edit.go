@@ -0,0 +1,192 @@
+package ssh
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/kevinburke/ssh_config"
+)
+
+// blockRe matches the lines starting a Host or Match block.
+var blockRe = regexp.MustCompile(`(?i)^[ \t]*(?:host|match)(?:[ \t]*=|[ \t]+)`)
+
+// blockPos is where a Host block starts in a config file.
+type blockPos struct {
+	file string
+	line int
+}
+
+// blockLines returns the line numbers of the Host lines in a config file, in
+// order, so they line up with its decoded blocks after the implicit first one.
+func blockLines(b []byte) []int {
+	var lines []int
+
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for n := 1; scanner.Scan(); n++ {
+		if blockRe.MatchString(scanner.Text()) {
+			lines = append(lines, n)
+		}
+	}
+
+	return lines
+}
+
+// recordPositions remembers where each block decoded from file starts.
+func (l *loader) recordPositions(file string, b []byte, cfg *ssh_config.Config) {
+	if l.positions == nil {
+		l.positions = map[*ssh_config.Host]blockPos{}
+	}
+
+	lines := blockLines(b)
+
+	// The first block holds any options before the first Host line, so has
+	// no line of its own.
+	for i, h := range cfg.Hosts[1:] {
+		if i < len(lines) {
+			l.positions[h] = blockPos{file: file, line: lines[i]}
+		}
+	}
+}
+
+// SetOption sets key to value in the Host block starting at line of file,
+// replacing the first existing value or adding it after the block's last
+// option.
+func SetOption(file string, line int, key, value string) error {
+	path, err := filepath.EvalSymlinks(file)
+	if err != nil {
+		return fmt.Errorf("could not resolve ssh config file %s: %w", file, err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("could not read ssh config file %s: %w", file, err)
+	}
+
+	lines := strings.Split(string(b), "\n")
+	if line < 1 || line > len(lines) || !blockRe.MatchString(lines[line-1]) {
+		return fmt.Errorf("%s:%d: not the start of a Host block", file, line)
+	}
+
+	lines = setOption(lines, line-1, key, value)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("could not stat ssh config file %s: %w", file, err)
+	}
+
+	return writeFileAtomic(path, []byte(strings.Join(lines, "\n")), info.Mode().Perm())
+}
+
+// setOption sets key in the block starting at index start of lines.
+func setOption(lines []string, start int, key, value string) []string {
+	end := len(lines)
+	for i := start + 1; i < len(lines); i++ {
+		if blockRe.MatchString(lines[i]) {
+			end = i
+			break
+		}
+	}
+
+	indent := "\t"
+	last := start
+
+	for i := start + 1; i < end; i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		lineIndent := lines[i][:len(lines[i])-len(strings.TrimLeft(lines[i], " \t"))]
+		if last == start {
+			indent = lineIndent
+		}
+
+		last = i
+
+		// The key ends at the first space, tab or =, e.g. "User\tbob" or
+		// "User=bob".
+		k := trimmed
+		if i := strings.IndexAny(trimmed, " \t="); i >= 0 {
+			k = trimmed[:i]
+		}
+
+		if strings.EqualFold(k, key) {
+			lines[i] = lineIndent + key + " " + value
+			return lines
+		}
+	}
+
+	return slices.Insert(lines, last+1, indent+key+" "+value)
+}
+
+// SetOptionAll sets key to value in the Host block of each host, as loaded by
+// LoadSSHConfig.
+func SetOptionAll(hosts []*Host, key, value string) error {
+	// Edit each file from the bottom up, so adding a line doesn't move the
+	// blocks still to be edited.
+	positions := make([]blockPos, 0, len(hosts))
+	for _, h := range hosts {
+		if h.pos.file == "" {
+			return fmt.Errorf("don't know where host %s is defined", h.Name)
+		}
+
+		if !slices.Contains(positions, h.pos) {
+			positions = append(positions, h.pos)
+		}
+	}
+
+	slices.SortFunc(positions, func(a, b blockPos) int {
+		if c := strings.Compare(a.file, b.file); c != 0 {
+			return c
+		}
+
+		return b.line - a.line
+	})
+
+	var errs []error
+
+	for _, p := range positions {
+		if err := SetOption(p.file, p.line, key, value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// writeFileAtomic replaces path with data, so a failed write can't leave a
+// half written config behind.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("could not create temporary file: %w", err)
+	}
+
+	defer func() { _ = os.Remove(f.Name()) }()
+
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("could not write %s: %w", path, err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("could not write %s: %w", path, err)
+	}
+
+	if err := os.Chmod(f.Name(), perm); err != nil {
+		return fmt.Errorf("could not set permissions on %s: %w", path, err)
+	}
+
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("could not replace %s: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package ssh
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetOption(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		line   int
+		want   string
+	}{
+		{
+			name:   "new option",
+			config: "Host web1\n  HostName 10.0.0.1\n\nHost db1\n  HostName 10.0.0.2\n",
+			line:   1,
+			want:   "Host web1\n  HostName 10.0.0.1\n  ForwardAgent yes\n\nHost db1\n  HostName 10.0.0.2\n",
+		},
+		{
+			name:   "overwrite, space separated",
+			config: "Host web1\n  forwardagent no\n  User alice\n",
+			line:   1,
+			want:   "Host web1\n  ForwardAgent yes\n  User alice\n",
+		},
+		{
+			name:   "overwrite, = separated",
+			config: "Host web1\n  User alice\n    ForwardAgent=no\n",
+			line:   1,
+			want:   "Host web1\n  User alice\n    ForwardAgent yes\n",
+		},
+		{
+			name:   "overwrite, tab separated",
+			config: "Host web1\n\tUser alice\n\tFORWARDAGENT\tno\n",
+			line:   1,
+			want:   "Host web1\n\tUser alice\n\tForwardAgent yes\n",
+		},
+		{
+			name:   "overwrite, spaced =",
+			config: "Host web1\n  ForwardAgent = no\n",
+			line:   1,
+			want:   "Host web1\n  ForwardAgent yes\n",
+		},
+		{
+			name:   "new option, tab separated",
+			config: "Host web1\n\tForwardX11\tno\n\tUser=alice\n",
+			line:   1,
+			want:   "Host web1\n\tForwardX11\tno\n\tUser=alice\n\tForwardAgent yes\n",
+		},
+		{
+			name:   "last block",
+			config: "Host web1\n  User alice\nHost db1\n  User bob\n",
+			line:   3,
+			want:   "Host web1\n  User alice\nHost db1\n  User bob\n  ForwardAgent yes\n",
+		},
+		{
+			name:   "empty block",
+			config: "Host web1\nHost db1\n",
+			line:   1,
+			want:   "Host web1\n\tForwardAgent yes\nHost db1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config")
+			if err := os.WriteFile(path, []byte(tt.config), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := SetOption(path, tt.line, "ForwardAgent", "yes"); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("config =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetOptionNotABlock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte("Host web1\n  User alice\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetOption(path, 2, "User", "bob"); err == nil {
+		t.Error("SetOption on an option line succeeded, want an error")
+	}
+}
+
+func TestSetOptionAll(t *testing.T) {
+	const config = "Host web1\n  HostName 10.0.0.1\nHost db1\n  HostName 10.0.0.2\nHost cache1\n  HostName 10.0.0.3\n"
+
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	hosts, err := LoadSSHConfig([]string{path}, LoadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var edit []*Host
+	for _, h := range hosts {
+		if h.Name != "db1" {
+			edit = append(edit, h)
+		}
+	}
+
+	if err := SetOptionAll(edit, "User", "deploy"); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "Host web1\n  HostName 10.0.0.1\n  User deploy\nHost db1\n  HostName 10.0.0.2\nHost cache1\n  HostName 10.0.0.3\n  User deploy\n"
+	if string(got) != want {
+		t.Errorf("config =\n%s\nwant\n%s", got, want)
+	}
+}
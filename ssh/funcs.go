@@ -0,0 +1,51 @@
+package ssh
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// tmplFuncs are the helpers available to command templates, a small subset of
+// sprig's with the same argument order so they read well in pipelines, e.g.
+// {{.User | default "root"}}:
+//
+//   - default DEFAULT VALUE: VALUE, or DEFAULT if VALUE renders empty
+//   - upper S, lower S, trim S
+//   - trimPrefix PREFIX S, trimSuffix SUFFIX S
+//   - replace OLD NEW S
+//   - quote S: S quoted for the shell, if needed
+//
+// Values are rendered as they would be in the template, so {{.ExtraArgs |
+// upper}} works on the quoted arguments.
+var tmplFuncs = template.FuncMap{
+	"default": func(def string, v any) string {
+		if s := toString(v); s != "" {
+			return s
+		}
+
+		return def
+	},
+	"upper": func(v any) string { return strings.ToUpper(toString(v)) },
+	"lower": func(v any) string { return strings.ToLower(toString(v)) },
+	"trim":  func(v any) string { return strings.TrimSpace(toString(v)) },
+	"trimPrefix": func(prefix string, v any) string {
+		return strings.TrimPrefix(toString(v), prefix)
+	},
+	"trimSuffix": func(suffix string, v any) string {
+		return strings.TrimSuffix(toString(v), suffix)
+	},
+	"replace": func(old, repl string, v any) string {
+		return strings.ReplaceAll(toString(v), old, repl)
+	},
+	"quote": func(v any) string { return shellQuote(toString(v)) },
+}
+
+// toString renders v as the template would, with nil as empty.
+func toString(v any) string {
+	if v == nil {
+		return ""
+	}
+
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,61 @@
+package ssh
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// fieldOptions are the options with a field of their own on Host, so left out
+// of OtherOptions.
+var fieldOptions = toSet("User", "Hostname", "Port", "ProxyCommand", "IdentityFile")
+
+// OtherOptions returns every option ssh would use for h, as Resolve does,
+// except those with a field of their own, keyed by lower case name. The
+// values of options given more than once, such as LocalForward, are joined
+// with ", ".
+func (h *Host) OtherOptions() map[string]string {
+	if h.otherOptions != nil {
+		return h.otherOptions
+	}
+
+	opts := make(map[string]string)
+
+	for _, o := range h.Resolve() {
+		key := strings.ToLower(o.Key)
+		if fieldOptions[key] {
+			continue
+		}
+
+		if v, ok := opts[key]; ok {
+			opts[key] = v + ", " + o.Value
+			continue
+		}
+
+		opts[key] = o.Value
+	}
+
+	return opts
+}
+
+// SetOtherOptions replaces the options OtherOptions returns for h, e.g. to
+// mask them before encoding h.
+func (h *Host) SetOtherOptions(opts map[string]string) {
+	h.otherOptions = opts
+}
+
+// MarshalJSON encodes h's fields along with its OtherOptions as "options".
+// Aliases are always an array, even when there are none.
+func (h *Host) MarshalJSON() ([]byte, error) {
+	// host has Host's fields but not this method, so they encode as usual.
+	type host Host
+
+	c := host(*h)
+	if c.Aliases == nil {
+		c.Aliases = []string{}
+	}
+
+	return json.Marshal(struct {
+		*host
+		Options map[string]string `json:"options,omitempty"`
+	}{&c, h.OtherOptions()})
+}
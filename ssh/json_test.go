@@ -0,0 +1,82 @@
+package ssh
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	hosts := loadConfig(t, `Host web1 www
+  User bob
+  Hostname 10.0.0.1
+  Port 2222
+  ForwardAgent yes
+  LocalForward 8080 localhost:80
+  LocalForward 8443 localhost:443
+
+Host *
+  ServerAliveInterval 30
+  User nobody
+`)
+
+	web1 := hosts[slices.IndexFunc(hosts, func(h *Host) bool { return h.Name == "web1" })]
+
+	data, err := json.Marshal(web1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got struct {
+		Host
+		Options map[string]string `json:"options"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("unmarshal %s: %v", data, err)
+	}
+
+	if got.Name != "web1" || got.User != "bob" || got.Hostname != "10.0.0.1" || got.Port != "2222" {
+		t.Errorf("round trip = %s@%s:%s (%s), want bob@10.0.0.1:2222 (web1)", got.User, got.Hostname, got.Port, got.Name)
+	}
+
+	if !slices.Equal(got.Aliases, []string{"www"}) {
+		t.Errorf("round trip aliases = %q, want [www]", got.Aliases)
+	}
+
+	// Options with a field of their own aren't repeated, while the rest,
+	// including those from Host *, are included.
+	want := map[string]string{
+		"forwardagent":        "yes",
+		"localforward":        "8080 localhost:80, 8443 localhost:443",
+		"serveraliveinterval": "30",
+	}
+	if !maps.Equal(got.Options, want) {
+		t.Errorf("round trip options = %v, want %v", got.Options, want)
+	}
+}
+
+func TestMarshalJSONSetOtherOptions(t *testing.T) {
+	h := &Host{Name: "web1"}
+	h.SetOtherOptions(map[string]string{"forwardagent": "***"})
+
+	data, err := json.Marshal(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := `{"name":"web1","aliases":[],"options":{"forwardagent":"***"}}`; string(data) != want {
+		t.Errorf("Marshal = %s, want %s", data, want)
+	}
+
+	h.SetOtherOptions(map[string]string{})
+
+	data, err = json.Marshal(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := `{"name":"web1","aliases":[]}`; string(data) != want {
+		t.Errorf("Marshal without options = %s, want %s", data, want)
+	}
+}
@@ -0,0 +1,73 @@
+package ssh
+
+import (
+	"slices"
+	"strings"
+)
+
+// MergeHosts combines hosts sharing a Name, such as a base definition and an
+// override in an included file, into the first one seen. Later hosts override
+// its User, Hostname, Port and ProxyCommand when they set them, and add their
+// aliases. Hosts named by a wildcard pattern are left alone.
+func MergeHosts(hosts []*Host) []*Host {
+	merged := make([]*Host, 0, len(hosts))
+	byName := make(map[string]*Host)
+
+	for _, h := range hosts {
+		if isWildcard(h.Name) {
+			merged = append(merged, h)
+			continue
+		}
+
+		first, ok := byName[h.Name]
+		if !ok {
+			byName[h.Name] = h
+			merged = append(merged, h)
+			continue
+		}
+
+		first.merge(h)
+	}
+
+	return merged
+}
+
+// merge overrides h's fields with those set by other.
+func (h *Host) merge(other *Host) {
+	if other.User != "" {
+		h.User = other.User
+	}
+
+	if other.setsHostname() {
+		h.Hostname = other.Hostname
+	}
+
+	if other.Port != "" {
+		h.Port = other.Port
+	}
+
+	if other.ProxyCommand != "" {
+		h.ProxyCommand = other.ProxyCommand
+	}
+
+	for _, a := range other.Aliases {
+		if !slices.Contains(h.Aliases, a) {
+			h.Aliases = append(h.Aliases, a)
+		}
+	}
+}
+
+// setsHostname reports whether h's Hostname was set rather than defaulted to
+// its name.
+func (h *Host) setsHostname() bool {
+	if h.original == nil {
+		return h.Hostname != ""
+	}
+
+	return h.Hostname != "" && getOptVal(h.original, "hostname") != ""
+}
+
+// isWildcard reports whether pattern matches more than one literal name.
+func isWildcard(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?!")
+}
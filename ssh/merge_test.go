@@ -0,0 +1,87 @@
+package ssh
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kevinburke/ssh_config"
+)
+
+func TestMergeHosts(t *testing.T) {
+	cfg, err := ssh_config.Decode(strings.NewReader(`Host web1
+  User bob
+  Hostname 10.0.0.1
+
+Host web1 web1-alias
+  Port 2222
+
+Host *.example.com
+  User admin
+
+Host web1 web1-old
+  Hostname 10.0.0.2
+  ProxyCommand ssh bastion nc %h %p
+
+Host db1
+
+Host *.example.com
+  Port 2200
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var hosts []*Host
+	for _, block := range cfg.Hosts[1:] {
+		hosts = append(hosts, NewHost(block))
+	}
+
+	merged := MergeHosts(hosts)
+
+	names := make([]string, len(merged))
+	for i, h := range merged {
+		names[i] = h.Name
+	}
+
+	wantNames := []string{"web1", "*.example.com", "db1", "*.example.com"}
+	if !slices.Equal(names, wantNames) {
+		t.Fatalf("merged hosts = %q, want %q", names, wantNames)
+	}
+
+	web1 := merged[0]
+	want := Host{
+		Name:         "web1",
+		Aliases:      []string{"web1-alias", "web1-old"},
+		User:         "bob",
+		Hostname:     "10.0.0.2",
+		Port:         "2222",
+		ProxyCommand: "ssh bastion nc %h %p",
+	}
+
+	if web1.User != want.User || web1.Hostname != want.Hostname || web1.Port != want.Port || web1.ProxyCommand != want.ProxyCommand {
+		t.Errorf("merged web1 = %s@%s:%s via %q, want %s@%s:%s via %q",
+			web1.User, web1.Hostname, web1.Port, web1.ProxyCommand,
+			want.User, want.Hostname, want.Port, want.ProxyCommand)
+	}
+
+	if !slices.Equal(web1.Aliases, want.Aliases) {
+		t.Errorf("merged web1 aliases = %q, want %q", web1.Aliases, want.Aliases)
+	}
+}
+
+func TestMergeHostsDefaultedHostname(t *testing.T) {
+	// A later block without a Hostname mustn't replace the real one with the
+	// name it defaults to.
+	first := &Host{Name: "web1", Hostname: "10.0.0.1"}
+	later := NewHost(decodeHost(t, "Host web1\n  User bob\n"))
+
+	merged := MergeHosts([]*Host{first, later})
+	if len(merged) != 1 {
+		t.Fatalf("merged into %d hosts, want 1", len(merged))
+	}
+
+	if merged[0].Hostname != "10.0.0.1" || merged[0].User != "bob" {
+		t.Errorf("merged web1 = %s@%s, want bob@10.0.0.1", merged[0].User, merged[0].Hostname)
+	}
+}
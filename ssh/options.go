@@ -0,0 +1,103 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/kevinburke/ssh_config"
+)
+
+// knownOptions are the ssh_config keywords understood by OpenSSH, lower cased.
+// This includes deprecated keywords that ssh still accepts.
+var knownOptions = toSet(
+	"Host", "Match", "AddKeysToAgent", "AddressFamily", "BatchMode",
+	"BindAddress", "BindInterface", "CanonicalDomains", "CanonicalizeFallbackLocal",
+	"CanonicalizeHostname", "CanonicalizeMaxDots", "CanonicalizePermittedCNAMEs",
+	"CASignatureAlgorithms", "CertificateFile", "ChallengeResponseAuthentication",
+	"ChannelTimeout", "CheckHostIP", "Cipher", "Ciphers", "ClearAllForwardings",
+	"Compression", "CompressionLevel", "ConnectionAttempts", "ConnectTimeout",
+	"ControlMaster", "ControlPath", "ControlPersist", "DynamicForward",
+	"EnableEscapeCommandline", "EnableSSHKeysign", "EscapeChar",
+	"ExitOnForwardFailure", "FingerprintHash", "ForkAfterAuthentication",
+	"ForwardAgent", "ForwardX11", "ForwardX11Timeout", "ForwardX11Trusted",
+	"GatewayPorts", "GlobalKnownHostsFile", "GSSAPIAuthentication",
+	"GSSAPIDelegateCredentials", "HashKnownHosts", "HostbasedAcceptedAlgorithms",
+	"HostbasedAuthentication", "HostbasedKeyTypes", "HostKeyAlgorithms",
+	"HostKeyAlias", "Hostname", "IdentitiesOnly", "IdentityAgent", "IdentityFile",
+	"IgnoreUnknown", "Include", "IPQoS", "KbdInteractiveAuthentication",
+	"KbdInteractiveDevices", "KexAlgorithms", "KnownHostsCommand", "LocalCommand",
+	"LocalForward", "LogLevel", "LogVerbose", "MACs",
+	"NoHostAuthenticationForLocalhost", "NumberOfPasswordPrompts",
+	"ObscureKeystrokeTiming", "PasswordAuthentication", "PermitLocalCommand",
+	"PermitRemoteOpen", "PKCS11Provider", "Port", "PreferredAuthentications",
+	"Protocol", "ProxyCommand", "ProxyJump", "ProxyUseFdpass",
+	"PubkeyAcceptedAlgorithms", "PubkeyAcceptedKeyTypes", "PubkeyAuthentication",
+	"RekeyLimit", "RemoteCommand", "RemoteForward", "RequestTTY", "RequiredRSASize",
+	"RevokedHostKeys", "RhostsRSAAuthentication", "RSAAuthentication",
+	"SecurityKeyProvider", "SendEnv", "ServerAliveCountMax", "ServerAliveInterval",
+	"SessionType", "SetEnv", "StdinNull", "StreamLocalBindMask",
+	"StreamLocalBindUnlink", "StrictHostKeyChecking", "SyslogFacility", "Tag",
+	"TCPKeepAlive", "Tunnel", "TunnelDevice", "UpdateHostKeys", "UseKeychain",
+	"UsePrivilegedPort", "User", "UserKnownHostsFile", "VerifyHostKeyDNS",
+	"VisualHostKey", "XAuthLocation",
+)
+
+func toSet(keys ...string) map[string]bool {
+	set := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		set[strings.ToLower(k)] = true
+	}
+
+	return set
+}
+
+// unknownOptions returns a Diagnostic for every unknown option in the blocks
+// decoded from file. Options matching an IgnoreUnknown pattern are allowed,
+// as ssh allows them.
+func unknownOptions(file string, blocks []*ssh_config.Host) []Diagnostic {
+	var ignore []string
+	for _, b := range blocks {
+		for _, v := range getOptVals(b, "ignoreunknown") {
+			ignore = append(ignore, strings.Split(v, ",")...)
+		}
+	}
+
+	var diags []Diagnostic
+
+	for _, b := range blocks {
+		for _, node := range b.Nodes {
+			kv, ok := node.(*ssh_config.KV)
+			if !ok || knownOptions[strings.ToLower(kv.Key)] || ignored(kv.Key, ignore) {
+				continue
+			}
+
+			diags = append(diags, Diagnostic{File: file, Line: kv.Pos().Line, Message: fmt.Sprintf("unknown option %q", kv.Key)})
+		}
+	}
+
+	return diags
+}
+
+// checkOptions returns an error listing every unknown option in the blocks
+// decoded from file.
+func checkOptions(file string, blocks []*ssh_config.Host) error {
+	var errs []error
+	for _, d := range unknownOptions(file, blocks) {
+		errs = append(errs, errors.New(d.String()))
+	}
+
+	return errors.Join(errs...)
+}
+
+// ignored reports whether key matches one of the IgnoreUnknown patterns.
+func ignored(key string, patterns []string) bool {
+	for _, p := range patterns {
+		if ok, _ := filepath.Match(strings.ToLower(strings.TrimSpace(p)), strings.ToLower(key)); ok {
+			return true
+		}
+	}
+
+	return false
+}
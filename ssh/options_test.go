@@ -0,0 +1,75 @@
+package ssh
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kevinburke/ssh_config"
+)
+
+func TestCheckOptions(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   []string
+	}{
+		{"known", "Host web1\n  HostName 10.0.0.1\n  forwardagent yes\n", nil},
+		{"unknown", "Host web1\n  FooBar yes\n", []string{`config:2: unknown option "FooBar"`}},
+		{"misspelled", "Host web1\n  HostNmae 10.0.0.1\n  Prot 22\n", []string{
+			`config:2: unknown option "HostNmae"`,
+			`config:3: unknown option "Prot"`,
+		}},
+		{"ignored", "IgnoreUnknown UseKeychain,Foo*\nHost web1\n  FooBar yes\n", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := ssh_config.Decode(strings.NewReader(tt.config))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = checkOptions("config", cfg.Hosts)
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Errorf("checkOptions = %v, want nil", err)
+				}
+
+				return
+			}
+
+			if err == nil || err.Error() != strings.Join(tt.want, "\n") {
+				t.Errorf("checkOptions = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnknownOptions(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   []Diagnostic
+	}{
+		{"known", "Host web1\n  HostName 10.0.0.1\n  forwardagent yes\n", nil},
+		{"unknown", "Host web1\n  FooBar yes\n", []Diagnostic{{"config", 2, `unknown option "FooBar"`}}},
+		{"misspelled", "Host web1\n  HostNmae 10.0.0.1\n  Prot 22\n", []Diagnostic{
+			{"config", 2, `unknown option "HostNmae"`},
+			{"config", 3, `unknown option "Prot"`},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := ssh_config.Decode(strings.NewReader(tt.config))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := unknownOptions("config", cfg.Hosts); !slices.Equal(got, tt.want) {
+				t.Errorf("unknownOptions = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package ssh
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// remoteTimeout is how long fetching a remote config may take.
+const remoteTimeout = 10 * time.Second
+
+// isRemote reports whether path is a config to fetch over HTTPS rather than a
+// local file. Plain HTTP isn't supported, as a config can run commands.
+func isRemote(path string) bool {
+	return strings.HasPrefix(path, "https://")
+}
+
+// fetch downloads a remote config, caching it so the last copy fetched is
+// used when the server can't be reached.
+func (l *loader) fetch(url string) ([]byte, error) {
+	cache, cacheErr := remoteCachePath(url)
+
+	b, err := fetchConfig(url)
+	if err != nil {
+		if cacheErr != nil {
+			return nil, err
+		}
+
+		cached, readErr := os.ReadFile(cache)
+		if readErr != nil {
+			return nil, err
+		}
+
+		l.warn(Diagnostic{File: url, Message: fmt.Sprintf("using cached copy: %v", err)})
+
+		return cached, nil
+	}
+
+	if cacheErr == nil {
+		if err := os.MkdirAll(filepath.Dir(cache), 0o700); err == nil {
+			err = os.WriteFile(cache, b, 0o600)
+		}
+
+		if err != nil {
+			l.warn(Diagnostic{File: url, Message: fmt.Sprintf("could not cache: %v", err)})
+		}
+	}
+
+	return b, nil
+}
+
+func fetchConfig(url string) ([]byte, error) {
+	client := &http.Client{Timeout: remoteTimeout}
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch ssh config %s: %w", url, err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not fetch ssh config %s: %s", url, resp.Status)
+	}
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch ssh config %s: %w", url, err)
+	}
+
+	return b, nil
+}
+
+// remoteCachePath is where the last copy of a remote config is kept, in
+// $XDG_CACHE_HOME/pssh/remote.
+func remoteCachePath(url string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("could not get user cache directory: %w", err)
+	}
+
+	sum := sha256.Sum256([]byte(url))
+
+	return filepath.Join(dir, "pssh", "remote", hex.EncodeToString(sum[:])), nil
+}
@@ -0,0 +1,62 @@
+package ssh
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFetch(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("Host web1\n"))
+	}))
+	defer srv.Close()
+
+	var warnings []Diagnostic
+	l := &loader{opts: LoadOptions{Warn: func(d Diagnostic) { warnings = append(warnings, d) }}}
+
+	// A fresh fetch is returned and cached
+	b, err := l.fetch(srv.URL)
+	if err != nil || string(b) != "Host web1\n" {
+		t.Fatalf("fetch = %q, %v", b, err)
+	}
+
+	if len(warnings) != 0 {
+		t.Errorf("warnings on a fresh fetch: %v", warnings)
+	}
+
+	// A failed fetch falls back to the cache with a warning
+	status = http.StatusInternalServerError
+
+	b, err = l.fetch(srv.URL)
+	if err != nil || string(b) != "Host web1\n" {
+		t.Fatalf("fetch from the cache = %q, %v", b, err)
+	}
+
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Message, "using cached copy") {
+		t.Errorf("warnings = %v, want one about the cached copy", warnings)
+	}
+
+	// Without a cached copy the status is reported
+	_, err = l.fetch(srv.URL + "/other")
+	if err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("fetch with no cache = %v, want the 500 status", err)
+	}
+}
+
+func TestIsRemote(t *testing.T) {
+	for path, want := range map[string]bool{
+		"https://example.com/config": true,
+		"http://example.com/config":  false,
+		"~/.ssh/config":              false,
+	} {
+		if got := isRemote(path); got != want {
+			t.Errorf("isRemote(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
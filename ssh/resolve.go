@@ -0,0 +1,148 @@
+package ssh
+
+import (
+	"strings"
+
+	"github.com/kevinburke/ssh_config"
+)
+
+// Option is a config option and its value.
+type Option struct {
+	Key   string
+	Value string
+}
+
+// multiValued are the options where ssh uses every value given, rather than
+// only the first.
+var multiValued = toSet(
+	"CertificateFile", "DynamicForward", "IdentityFile", "LocalForward",
+	"RemoteForward", "SendEnv", "SetEnv",
+)
+
+// Resolve returns the options ssh would use to connect to h, like `ssh -G`
+// but without ssh's built in defaults: the first value of each option across
+// every matching Host block, in config order.
+func (h *Host) Resolve() []Option {
+	var opts []Option
+
+	seen := map[string]bool{}
+
+	for _, b := range h.blocks() {
+		if b != h.original && !b.Matches(h.Name) {
+			continue
+		}
+
+		for _, node := range b.Nodes {
+			kv, ok := node.(*ssh_config.KV)
+			if !ok {
+				continue
+			}
+
+			key := strings.ToLower(kv.Key)
+			if seen[key] && !multiValued[key] {
+				continue
+			}
+
+			seen[key] = true
+			opts = append(opts, Option{Key: kv.Key, Value: kv.Value})
+		}
+	}
+
+	if !seen["hostname"] {
+		opts = append(opts, Option{Key: "Hostname", Value: h.Name})
+	}
+
+	return opts
+}
+
+// blocks returns the blocks the host was loaded with, or just its own if it
+// wasn't loaded from a config.
+func (h *Host) blocks() []*ssh_config.Host {
+	if h.config == nil && h.original != nil {
+		return []*ssh_config.Host{h.original}
+	}
+
+	return h.config
+}
+
+// HasConcreteHostname reports whether a Hostname option applies to h, rather
+// than ssh falling back to the pattern itself as for templates and fragments.
+func (h *Host) HasConcreteHostname() bool {
+	return h.Hostname != "" && resolveEffective(h.blocks(), h.Name, "hostname") != ""
+}
+
+// IsProxied reports whether ssh connects to h through a ProxyCommand or
+// ProxyJump set by any block matching it, or its own ProxyCommand for a host
+// not loaded from a config.
+func (h *Host) IsProxied() bool {
+	blocks := h.blocks()
+
+	return h.ProxyCommand != "" ||
+		resolveEffective(blocks, h.Name, "proxycommand") != "" ||
+		resolveEffective(blocks, h.Name, "proxyjump") != ""
+}
+
+// IsEmpty reports whether h's own block sets no options, as for placeholder
+// Host blocks holding only comments.
+func (h *Host) IsEmpty() bool {
+	if h.original == nil {
+		return false
+	}
+
+	for _, node := range h.original.Nodes {
+		if _, ok := node.(*ssh_config.KV); ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Options returns the options set in h's own block, in config order. Unlike
+// Resolve, options from other matching blocks aren't included.
+func (h *Host) Options() []Option {
+	if h.original == nil {
+		return nil
+	}
+
+	var opts []Option
+
+	for _, node := range h.original.Nodes {
+		if kv, ok := node.(*ssh_config.KV); ok {
+			opts = append(opts, Option{Key: kv.Key, Value: kv.Value})
+		}
+	}
+
+	return opts
+}
+
+// Comments returns the text of the comments in h's own block, including one
+// on its Host line, in config order.
+func (h *Host) Comments() []string {
+	if h.original == nil {
+		return nil
+	}
+
+	var comments []string
+	if c := strings.TrimSpace(h.original.EOLComment); c != "" {
+		comments = append(comments, c)
+	}
+
+	for _, node := range h.original.Nodes {
+		var c string
+
+		switch n := node.(type) {
+		case *ssh_config.KV:
+			c = n.Comment
+		case *ssh_config.Empty:
+			c = n.Comment
+		}
+
+		// Include lines are rewritten into comments while loading
+		if c = strings.TrimSpace(c); c != "" && !strings.HasPrefix(c, includeMarker) {
+			comments = append(comments, c)
+		}
+	}
+
+	return comments
+}
@@ -0,0 +1,164 @@
+package ssh
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	const config = `Host web1
+  HostName 10.0.0.1
+  User deploy
+  IdentityFile ~/.ssh/web
+
+Host db1
+
+Host *
+  User root
+  Port 2222
+  IdentityFile ~/.ssh/default
+`
+
+	byName := map[string]*Host{}
+	for _, h := range loadConfig(t, config) {
+		byName[h.Name] = h
+	}
+
+	tests := []struct {
+		name string
+		want []Option
+	}{
+		{"web1", []Option{
+			{"HostName", "10.0.0.1"},
+			{"User", "deploy"},
+			{"IdentityFile", "~/.ssh/web"},
+			{"Port", "2222"},
+			{"IdentityFile", "~/.ssh/default"},
+		}},
+		// Without a HostName, ssh connects to the name itself
+		{"db1", []Option{
+			{"User", "root"},
+			{"Port", "2222"},
+			{"IdentityFile", "~/.ssh/default"},
+			{"Hostname", "db1"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := byName[tt.name]
+			if h == nil {
+				t.Fatalf("no host %s", tt.name)
+			}
+
+			if got := h.Resolve(); !slices.Equal(got, tt.want) {
+				t.Errorf("Resolve() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConcreteOnly(t *testing.T) {
+	const config = `Host web1
+  HostName 10.0.0.1
+
+Host base
+  User root
+
+Host db1
+
+Host db*
+  HostName db.example.com
+`
+
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	hosts, err := LoadSSHConfig([]string{path}, LoadOptions{ConcreteOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, h := range hosts {
+		names = append(names, h.Name)
+	}
+
+	slices.Sort(names)
+
+	// db1 gets its HostName from the db* block, and base has none
+	if want := []string{"db*", "db1", "web1"}; !slices.Equal(names, want) {
+		t.Errorf("hosts = %q, want %q", names, want)
+	}
+}
+
+func TestHideEmpty(t *testing.T) {
+	const config = `Host web1
+  HostName 10.0.0.1
+
+Host placeholder
+  # TODO: fill in
+
+Host db1
+
+Host db*
+  HostName db.example.com
+`
+
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	hosts, err := LoadSSHConfig([]string{path}, LoadOptions{HideEmpty: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, h := range hosts {
+		names = append(names, h.Name)
+	}
+
+	slices.Sort(names)
+
+	// db1's block is empty, but it gets a HostName from db*
+	if want := []string{"db*", "db1", "web1"}; !slices.Equal(names, want) {
+		t.Errorf("hosts = %q, want %q", names, want)
+	}
+
+	if (&Host{Name: "adhoc"}).IsEmpty() {
+		t.Error("a host not loaded from a config is empty")
+	}
+}
+
+func TestComments(t *testing.T) {
+	const config = `Host web1 # frontend
+  # role: web
+  HostName 10.0.0.1 # primary
+  ProxyJump bastion
+
+Host db1
+  HostName 10.0.0.2
+`
+
+	want := map[string][]string{
+		"web1": {"frontend", "role: web", "primary"},
+		"db1":  nil,
+	}
+
+	for _, h := range loadConfig(t, config) {
+		w, ok := want[h.Name]
+		if !ok {
+			continue
+		}
+
+		if got := h.Comments(); !slices.Equal(got, w) {
+			t.Errorf("%s comments = %q, want %q", h.Name, got, w)
+		}
+	}
+}
@@ -3,33 +3,81 @@ package ssh
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
-	"html/template"
+	"io"
+	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"text/template"
 
 	"github.com/kevinburke/ssh_config"
 )
 
+const (
+	// SystemConfig is the system-wide ssh config file.
+	SystemConfig = "/etc/ssh/ssh_config"
+	// UserConfig is the per-user ssh config file.
+	UserConfig = "~/.ssh/config"
+)
+
 type Host struct {
 	// Name is the primary pattern used to match this host entry.
-	Name string
+	Name string `json:"name"`
 	// Aliases are other patterns that match this host entry.
-	Aliases string
+	// They encode as an empty array rather than being left out, so scripts
+	// can rely on the key.
+	Aliases []string `json:"aliases"`
 	// User is the username for the SSH connection.
-	User string
+	User string `json:"user,omitempty"`
 	// Hostname is the actual remote hostname to connect to.
-	Hostname string
+	Hostname string `json:"hostname,omitempty"`
 	// Port is the port number for the SSH connection.
-	Port string
+	Port string `json:"port,omitempty"`
 	// ProxyCommand is the command to use to connect to the server.
-	ProxyCommand string
+	ProxyCommand string `json:"proxy_command,omitempty"`
+	// Retries caps how many times a failed connection is retried, set with a
+	// `#retries: N` comment in the host block. Zero means no limit.
+	Retries int `json:"retries,omitempty"`
+	// SetEnv holds the environment variables sent to the server, merged from
+	// all SetEnv lines with the first value for a variable winning as in ssh.
+	SetEnv map[string]string `json:"set_env,omitempty"`
+	// Forwards are the port forwards set up on connection, e.g.
+	// "LocalForward 8080 localhost:80".
+	Forwards []string `json:"forwards,omitempty"`
+	// IdentityFile is the first key ssh tries for the host.
+	IdentityFile string `json:"identity_file,omitempty"`
+	// IdentityFiles are the keys ssh tries for the host, in config order, with
+	// a leading ~/ expanded.
+	IdentityFiles []string `json:"identity_files,omitempty"`
+	// CanonicalHostname is the name ssh connects to after CanonicalizeHostname,
+	// if it applies, guessed from the first of CanonicalDomains.
+	CanonicalHostname string `json:"canonical_hostname,omitempty"`
+	// Profile is the pssh profile the host was loaded from, if any.
+	Profile string `json:"profile,omitempty"`
+	// Description is a note about the host, read from a descriptions file.
+	Description string `json:"description,omitempty"`
+	// SourceFile is the config file the host's block is in, and SourceLine
+	// the line its Host line is on.
+	SourceFile string `json:"source_file,omitempty"`
+	SourceLine int    `json:"source_line,omitempty"`
 
 	// original is a reference to the ssh_config.Host for other properties
 	original *ssh_config.Host
+	// pos is where the host's block starts, for editing it.
+	pos blockPos
+	// config is every block loaded along with the host, for resolving its
+	// options.
+	config []*ssh_config.Host
+	// otherOptions replaces the options resolved for OtherOptions, if set.
+	otherOptions map[string]string
 }
 
 func NewHost(host *ssh_config.Host) *Host {
@@ -38,15 +86,11 @@ func NewHost(host *ssh_config.Host) *Host {
 		name = host.Patterns[0].String()
 	}
 
-	aliases := ""
-
+	var aliases []string
 	if len(host.Patterns) > 1 {
-		var aliasPatterns []string
 		for _, p := range host.Patterns[1:] {
-			aliasPatterns = append(aliasPatterns, p.String())
+			aliases = append(aliases, p.String())
 		}
-
-		aliases = fmt.Sprintf("(%s)", joinStrings(aliasPatterns))
 	}
 
 	hostname := getOptVal(host, "hostname")
@@ -54,14 +98,156 @@ func NewHost(host *ssh_config.Host) *Host {
 		hostname = host.Patterns[0].String()
 	}
 
+	hostname, port := splitHostPort(hostname, getOptVal(host, "port"))
+
+	identities := getOptVals(host, "identityfile")
+	for i, f := range identities {
+		identities[i] = ExpandHome(f)
+	}
+
+	var identity string
+	if len(identities) > 0 {
+		identity = identities[0]
+	}
+
+	retries, err := strconv.Atoi(getAnnotation(host, "retries"))
+	if err != nil || retries < 0 {
+		retries = 0
+	}
+
 	return &Host{
-		Name:         name,
-		Aliases:      aliases,
-		User:         getOptVal(host, "user"),
-		Hostname:     hostname,
-		Port:         getOptVal(host, "port"),
-		ProxyCommand: getOptVal(host, "proxycommand"),
-		original:     host,
+		Name:          name,
+		Aliases:       aliases,
+		User:          getOptVal(host, "user"),
+		Hostname:      hostname,
+		Port:          port,
+		ProxyCommand:  getOptVal(host, "proxycommand"),
+		Retries:       retries,
+		SetEnv:        parseSetEnv(getOptVals(host, "setenv")),
+		Forwards:      getForwards(host),
+		IdentityFile:  identity,
+		IdentityFiles: identities,
+		original:      host,
+	}
+}
+
+// splitHostPort handles the non-standard `Hostname example.com:2222` form,
+// returning the host and, if port isn't already set, the embedded port.
+// Bracketed IPv6 addresses ([::1]:2222) are unwrapped, while bare ones are
+// left alone as their colons aren't a port separator.
+func splitHostPort(hostname, port string) (string, string) {
+	var host, embedded string
+
+	if rest, ok := strings.CutPrefix(hostname, "["); ok {
+		inner, after, found := strings.Cut(rest, "]")
+		if !found {
+			return hostname, port
+		}
+
+		host = inner
+		embedded, _ = strings.CutPrefix(after, ":")
+	} else {
+		h, p, found := strings.Cut(hostname, ":")
+		if !found || strings.Contains(p, ":") {
+			return hostname, port
+		}
+
+		host, embedded = h, p
+	}
+
+	if embedded != "" {
+		if _, err := strconv.Atoi(embedded); err != nil {
+			return hostname, port
+		}
+	}
+
+	if port == "" {
+		port = embedded
+	}
+
+	return host, port
+}
+
+// explodeHost builds a separate Host for each pattern of a multi-pattern Host
+// block, all sharing the block's options. Negated and match-all patterns are
+// skipped as they can't be connected to.
+func explodeHost(host *ssh_config.Host) []*Host {
+	hostname := getOptVal(host, "hostname")
+	hosts := make([]*Host, 0, len(host.Patterns))
+
+	for _, p := range host.Patterns {
+		// String drops the "!" of a negated pattern, but the block never
+		// matches a negated pattern's own text.
+		pattern := p.String()
+		if pattern == "*" || !host.Matches(pattern) {
+			continue
+		}
+
+		h := NewHost(host)
+		h.Name = pattern
+		h.Aliases = nil
+
+		if hostname == "" {
+			h.Hostname = pattern
+		}
+
+		hosts = append(hosts, h)
+	}
+
+	return hosts
+}
+
+// DisplayName returns the host name with suffix stripped, for display only.
+// The full Name is still used when connecting.
+func (h *Host) DisplayName(suffix string) string {
+	if suffix == "" {
+		return h.Name
+	}
+
+	if short, ok := strings.CutSuffix(h.Name, suffix); ok && short != "" {
+		return short
+	}
+
+	return h.Name
+}
+
+// AliasFormat is how DisplayAliases renders a host's aliases.
+type AliasFormat string
+
+const (
+	// AliasParen renders aliases as "(a, b)".
+	AliasParen AliasFormat = "paren"
+	// AliasComma renders aliases as "a, b".
+	AliasComma AliasFormat = "comma"
+	// AliasHidden doesn't render aliases.
+	AliasHidden AliasFormat = "hidden"
+)
+
+// ParseAliasFormat validates an --alias-format value.
+func ParseAliasFormat(s string) (AliasFormat, error) {
+	switch AliasFormat(s) {
+	case AliasParen, AliasComma, AliasHidden:
+		return AliasFormat(s), nil
+	default:
+		return "", fmt.Errorf("invalid alias format %q, must be %q, %q or %q", s, AliasParen, AliasComma, AliasHidden)
+	}
+}
+
+// DisplayAliases returns the aliases as shown in the TUI in the given format,
+// e.g. "(a, b)", or an empty string when there are none. The zero format is
+// AliasParen.
+func (h *Host) DisplayAliases(format AliasFormat) string {
+	if len(h.Aliases) == 0 {
+		return ""
+	}
+
+	switch format {
+	case AliasComma:
+		return joinStrings(h.Aliases)
+	case AliasHidden:
+		return ""
+	default:
+		return fmt.Sprintf("(%s)", joinStrings(h.Aliases))
 	}
 }
 
@@ -92,91 +278,522 @@ func getOptVal(host *ssh_config.Host, opt string) string {
 	return ""
 }
 
-func loadSSHConfig(path, home string) ([]*ssh_config.Host, error) {
-	var fp string
+// ConfigPaths returns the config files to load, in precedence order. By
+// default sshConfig is loaded along with the user and system configs; only
+// restricts loading to sshConfig alone.
+func ConfigPaths(sshConfig string, only bool) []string {
+	if only {
+		return []string{sshConfig}
+	}
 
-	if strings.HasPrefix(path, "~/") {
-		fp = filepath.Join(home, path[2:])
-	} else {
-		fp = path
+	paths := []string{sshConfig}
+	for _, p := range []string{UserConfig, SystemConfig} {
+		if p != sshConfig {
+			paths = append(paths, p)
+		}
+	}
+
+	return paths
+}
+
+// getOptVals returns every value of a repeatable option, in config order.
+func getOptVals(host *ssh_config.Host, opt string) []string {
+	var vals []string
+
+	for _, node := range host.Nodes {
+		if kv, ok := node.(*ssh_config.KV); ok {
+			if strings.EqualFold(kv.Key, opt) {
+				vals = append(vals, kv.Value)
+			}
+		}
+	}
+
+	return vals
+}
+
+// forwardOpts are the options which set up port forwarding.
+var forwardOpts = []string{"LocalForward", "RemoteForward", "DynamicForward"}
+
+// getForwards returns every forward in the host block, prefixed with its kind.
+func getForwards(host *ssh_config.Host) []string {
+	var forwards []string
+
+	for _, opt := range forwardOpts {
+		for _, v := range getOptVals(host, opt) {
+			forwards = append(forwards, opt+" "+v)
+		}
 	}
 
+	return forwards
+}
+
+// parseSetEnv merges SetEnv values of the form `NAME=value NAME2="other value"`.
+func parseSetEnv(lines []string) map[string]string {
+	var env map[string]string
+
+	for _, line := range lines {
+		words, err := splitArgs(line)
+		if err != nil {
+			continue
+		}
+
+		for _, w := range words {
+			k, v, ok := strings.Cut(w, "=")
+			if !ok || k == "" {
+				continue
+			}
+
+			if env == nil {
+				env = make(map[string]string)
+			}
+
+			if _, seen := env[k]; !seen {
+				env[k] = v
+			}
+		}
+	}
+
+	return env
+}
+
+// SetEnvOpts renders SetEnv as an ssh `-o SetEnv=...` option, for templates
+// which don't let ssh read the host's config block themselves.
+func (h *Host) SetEnvOpts() Args {
+	if len(h.SetEnv) == 0 {
+		return nil
+	}
+
+	vars := make([]string, 0, len(h.SetEnv))
+	for _, k := range slices.Sorted(maps.Keys(h.SetEnv)) {
+		vars = append(vars, k+"="+shellQuote(h.SetEnv[k]))
+	}
+
+	return Args{"-o", "SetEnv=" + strings.Join(vars, " ")}
+}
+
+// getAnnotation returns the value of a `#key: value` comment line in the host
+// block, used for pssh specific settings that ssh itself ignores.
+func getAnnotation(host *ssh_config.Host, key string) string {
+	for _, node := range host.Nodes {
+		if e, ok := node.(*ssh_config.Empty); ok {
+			k, v, found := strings.Cut(e.Comment, ":")
+			if found && strings.EqualFold(strings.TrimSpace(k), key) {
+				return strings.TrimSpace(v)
+			}
+		}
+	}
+
+	return ""
+}
+
+// ConnectFlags returns the pssh flags set for the host with a
+// `#pssh: --flag value` comment in its block, split like a shell would.
+func (h *Host) ConnectFlags() ([]string, error) {
+	if h.original == nil {
+		return nil, nil
+	}
+
+	args, err := splitArgs(getAnnotation(h.original, "pssh"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid #pssh flags for %s: %w", h.Name, err)
+	}
+
+	return args, nil
+}
+
+// includeMarker replaces the Include keyword before decoding, turning the line
+// into a comment so the loader follows includes itself: the parser resolves
+// them against ~/.ssh and recurses into cycles until it gives up.
+const includeMarker = "pssh-include "
+
+var includeRe = regexp.MustCompile(`(?im)^([ \t]*)include(?:[ \t]*=[ \t]*|[ \t]+)`)
+
+// loader loads config files, following their includes.
+type loader struct {
+	home string
+	opts LoadOptions
+	// loading holds the absolute paths of the files being loaded, to catch
+	// include cycles.
+	loading map[string]bool
+	// positions holds where each loaded block starts.
+	positions map[*ssh_config.Host]blockPos
+}
+
+func (l *loader) warn(d Diagnostic) {
+	if l.opts.Warn != nil {
+		l.opts.Warn(d)
+	}
+}
+
+func (l *loader) load(path string) ([]*ssh_config.Host, error) {
+	if isRemote(path) {
+		b, err := l.fetch(path)
+		if err != nil {
+			return nil, err
+		}
+
+		return l.parseConfig(path, bytes.NewReader(b))
+	}
+
+	fp := expandHome(path, l.home)
+
 	f, err := os.Open(filepath.Clean(fp))
 	if err != nil {
-		if path == "/etc/ssh/ssh_config" && os.IsNotExist(err) {
-			return nil, nil
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("could not open ssh config file %s: %w", fp, err)
+		}
+
+		// The default config files are optional, and a missing config
+		// shouldn't stop the others from loading.
+		if path != SystemConfig && path != UserConfig {
+			l.warn(Diagnostic{File: fp, Message: "skipping missing ssh config file"})
 		}
 
-		return nil, fmt.Errorf("could not open ssh config file %s: %w", fp, err)
+		return nil, nil
 	}
 
 	defer func() { _ = f.Close() }()
 
-	cfg, err := ssh_config.Decode(f)
+	abs, err := filepath.Abs(fp)
 	if err != nil {
-		return nil, fmt.Errorf("could not decode ssh config file %s: %w", fp, err)
+		return nil, fmt.Errorf("could not resolve ssh config file %s: %w", fp, err)
+	}
+
+	return l.parseConfig(abs, f)
+}
+
+// parseConfig decodes the config read from r, which was loaded from file,
+// following its includes.
+func (l *loader) parseConfig(file string, r io.Reader) ([]*ssh_config.Host, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("could not read ssh config file %s: %w", file, err)
+	}
+
+	if l.loading == nil {
+		l.loading = map[string]bool{}
+	}
+
+	l.loading[file] = true
+	defer delete(l.loading, file)
+
+	b = includeRe.ReplaceAll(b, []byte("${1}#"+includeMarker))
+
+	cfg, err := ssh_config.DecodeBytes(b)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode ssh config file %s: %w", file, err)
+	}
+
+	l.recordPositions(file, b, cfg)
+
+	if l.opts.Strict {
+		if err := checkOptions(file, cfg.Hosts); err != nil {
+			return nil, err
+		}
+	} else {
+		for _, d := range unknownOptions(file, cfg.Hosts) {
+			l.warn(d)
+		}
 	}
 
 	hosts := make([]*ssh_config.Host, 0, len(cfg.Hosts))
 
 	for _, h := range cfg.Hosts {
+		// Hosts keep the order they appear in: the block, then any hosts
+		// included from within it.
+		hosts = append(hosts, h)
+
 		for _, node := range h.Nodes {
-			if after, ok := strings.CutPrefix(strings.TrimSpace(strings.ToLower(node.String())), "include "); ok {
-				incPath := strings.TrimSpace(after)
+			inc, ok := node.(*ssh_config.Empty)
+			if !ok || !strings.HasPrefix(inc.Comment, includeMarker) {
+				continue
+			}
 
-				includedHosts, err := loadSSHConfig(incPath, home)
+			for _, incPath := range strings.Fields(strings.TrimPrefix(inc.Comment, includeMarker)) {
+				included, err := l.include(file, inc.Pos().Line, incPath)
 				if err != nil {
 					return nil, err
 				}
 
-				hosts = append(hosts, includedHosts...)
+				hosts = append(hosts, included...)
 			}
 		}
+	}
+
+	return hosts, nil
+}
+
+// include loads the files matching an Include pattern found at line of file,
+// skipping any which would include itself again.
+func (l *loader) include(file string, line int, pattern string) ([]*ssh_config.Host, error) {
+	path := expandHome(pattern, l.home)
+	if !filepath.IsAbs(path) {
+		// Relative to the including file, which for the default configs is
+		// ssh's own ~/.ssh or /etc/ssh. Remote configs can only include
+		// local files, so they act like the user config.
+		base := filepath.Dir(file)
+		if isRemote(file) {
+			base = filepath.Join(l.home, ".ssh")
+		}
+
+		path = filepath.Join(base, path)
+	}
+
+	// Include may be a glob, e.g. /etc/ssh/ssh_config.d/*.conf
+	matches, err := filepath.Glob(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid include pattern %s: %w", pattern, err)
+	}
 
-		if len(h.Patterns) == 0 || h.Patterns[0].String() == "*" {
+	// ssh ignores includes matching nothing, but a plain path which doesn't
+	// exist is likely a mistake. An empty glob usually isn't.
+	if len(matches) == 0 && !isWildcard(path) {
+		l.warn(Diagnostic{File: file, Line: line, Message: fmt.Sprintf("included file %s does not exist", path)})
+	}
+
+	var hosts []*ssh_config.Host
+
+	for _, match := range matches {
+		if abs, err := filepath.Abs(match); err == nil && l.loading[abs] {
+			l.warn(Diagnostic{File: file, Line: line, Message: fmt.Sprintf("skipping circular include of %s", match)})
 			continue
 		}
 
-		hosts = append(hosts, h)
+		included, err := l.load(match)
+		if err != nil {
+			return nil, err
+		}
+
+		hosts = append(hosts, included...)
 	}
 
 	return hosts, nil
 }
 
-func LoadSSHConfig(paths []string) ([]*Host, error) {
-	var allSSHHosts []*ssh_config.Host
+// warnDuplicates warns about each host defined again after its first
+// definition, which MergeHosts will fold into the first.
+func (l *loader) warnDuplicates(hosts []*Host) {
+	first := make(map[string]blockPos)
+
+	for _, h := range hosts {
+		if isWildcard(h.Name) {
+			continue
+		}
+
+		pos, ok := first[h.Name]
+		if !ok {
+			first[h.Name] = h.pos
+			continue
+		}
+
+		if h.pos == pos {
+			// Another pattern of the same block, when exploding patterns
+			continue
+		}
+
+		l.warn(Diagnostic{File: h.pos.file, Line: h.pos.line, Message: fmt.Sprintf("host %s is already defined at %s:%d", h.Name, pos.file, pos.line)})
+	}
+}
+
+// isSelectable reports whether a Host block names a host that can be listed,
+// rather than only holding options for every host.
+func isSelectable(h *ssh_config.Host) bool {
+	return len(h.Patterns) > 0 && h.Patterns[0].String() != "*"
+}
+
+// IsPattern reports whether the host is named by a glob pattern, such as
+// "*.internal" or "web-?", which holds options for other hosts rather than
+// being a host to connect to.
+func (h *Host) IsPattern() bool {
+	return isWildcard(h.Name)
+}
+
+// Hash returns a digest of the host's config block, which changes whenever
+// the block does.
+func (h *Host) Hash() string {
+	if h.original == nil {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(h.original.String()))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// resolveEffective returns the value ssh would use for key when connecting to
+// alias: the first value set by any matching Host block, in config order.
+func resolveEffective(blocks []*ssh_config.Host, alias, key string) string {
+	for _, b := range blocks {
+		if !b.Matches(alias) {
+			continue
+		}
+
+		if v := getOptVal(b, key); v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
+
+// applyEffective replaces h's summary fields with the values resolved across
+// all blocks, so options inherited from e.g. `Host *` show up.
+func applyEffective(h *Host, blocks []*ssh_config.Host) {
+	h.User = resolveEffective(blocks, h.Name, "user")
+	h.ProxyCommand = resolveEffective(blocks, h.Name, "proxycommand")
+
+	hostname := resolveEffective(blocks, h.Name, "hostname")
+	if hostname == "" {
+		hostname = h.Name
+	}
+
+	h.Hostname, h.Port = splitHostPort(hostname, resolveEffective(blocks, h.Name, "port"))
+
+	// ssh tries the keys of every matching block, so they're all kept, with
+	// the first resolved as usual.
+	h.IdentityFile = ExpandHome(resolveEffective(blocks, h.Name, "identityfile"))
+	h.IdentityFiles = nil
+
+	for _, b := range blocks {
+		if b.Matches(h.Name) {
+			for _, f := range getOptVals(b, "identityfile") {
+				h.IdentityFiles = append(h.IdentityFiles, ExpandHome(f))
+			}
+		}
+	}
+}
+
+// ExpandHome replaces a leading ~/ in path with the user's home directory.
+func ExpandHome(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+
+	return expandHome(path, home)
+}
+
+// expandHome replaces a leading ~/ in path with home.
+func expandHome(path, home string) string {
+	if strings.HasPrefix(path, "~/") {
+		return filepath.Join(home, path[2:])
+	}
+
+	return path
+}
+
+// LoadOptions tweaks how hosts are built from the parsed config.
+type LoadOptions struct {
+	// ExplodePatterns makes each pattern of a multi-pattern Host block its own
+	// host instead of an alias. Hosts are then not grouped by hostname.
+	ExplodePatterns bool
+	// ResolveEffective resolves each host's User, Hostname, Port, ProxyCommand
+	// and identity files with ssh's first-match semantics across the whole
+	// config, instead of reading only the host's own block.
+	ResolveEffective bool
+	// Strict fails loading if any option isn't a known ssh_config keyword.
+	Strict bool
+	// ConcreteOnly drops hosts without a Hostname option, which are often
+	// only templates or fragments of config.
+	ConcreteOnly bool
+	// HideEmpty drops hosts whose block sets no options and which have no
+	// concrete hostname, such as placeholder blocks.
+	HideEmpty bool
+	// Warn, if set, is called with problems that don't stop loading, such as
+	// circular or missing includes, unknown options when not Strict and hosts
+	// defined more than once.
+	Warn func(Diagnostic)
+}
+
+func LoadSSHConfig(paths []string, opts LoadOptions) ([]*Host, error) {
+	var allBlocks []*ssh_config.Host
 
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return nil, fmt.Errorf("could not get user home directory: %w", err)
 	}
 
+	l := &loader{home: home, opts: opts}
+
 	for _, p := range paths {
-		hosts, err := loadSSHConfig(p, home)
+		hosts, err := l.load(p)
 		if err != nil {
 			return nil, err
 		}
 
-		allSSHHosts = append(allSSHHosts, hosts...)
+		allBlocks = append(allBlocks, hosts...)
+	}
+
+	allHosts := make([]*Host, 0, len(allBlocks))
+
+	for _, b := range allBlocks {
+		if !isSelectable(b) {
+			continue
+		}
+
+		var built []*Host
+		if opts.ExplodePatterns {
+			built = explodeHost(b)
+		} else {
+			built = []*Host{NewHost(b)}
+		}
+
+		for _, h := range built {
+			h.pos = l.positions[b]
+			h.SourceFile, h.SourceLine = h.pos.file, h.pos.line
+			h.config = allBlocks
+		}
+
+		allHosts = append(allHosts, built...)
+	}
+
+	for _, h := range allHosts {
+		if opts.ResolveEffective {
+			applyEffective(h, allBlocks)
+		}
+
+		h.CanonicalHostname = h.canonicalHostname()
+	}
+
+	if opts.ConcreteOnly {
+		allHosts = slices.DeleteFunc(allHosts, func(h *Host) bool {
+			return !h.HasConcreteHostname()
+		})
 	}
 
-	allHosts := make([]*Host, 0, len(allSSHHosts))
+	l.warnDuplicates(allHosts)
+
+	allHosts = MergeHosts(allHosts)
+
+	if opts.HideEmpty {
+		allHosts = slices.DeleteFunc(allHosts, func(h *Host) bool {
+			return h.IsEmpty() && !h.HasConcreteHostname()
+		})
+	}
 
-	for _, h := range allSSHHosts {
-		allHosts = append(allHosts, NewHost(h))
+	if opts.ExplodePatterns {
+		return allHosts, nil
 	}
 
 	// TODO: Figure out if we want to group AND include all hosts with the same hostname
 	// or just the grouped one.
 	// Group hosts by hostname
 	groupedHosts := make(map[string][]*Host)
+
+	var hostnames []string // in the order first seen, so hosts keep config order
+
 	for _, h := range allHosts {
+		if _, ok := groupedHosts[h.Hostname]; !ok {
+			hostnames = append(hostnames, h.Hostname)
+		}
+
 		groupedHosts[h.Hostname] = append(groupedHosts[h.Hostname], h)
 	}
 
 	hosts := make([]*Host, 0, len(groupedHosts))
 
-	for _, group := range groupedHosts {
+	for _, hostname := range hostnames {
+		group := groupedHosts[hostname]
 		if len(group) == 0 {
 			continue
 		}
@@ -197,38 +814,206 @@ func LoadSSHConfig(paths []string) ([]*Host, error) {
 
 		// also add any existing aliases
 		for _, h := range group {
-			if h.Aliases != "" {
-				aliases = append(aliases, h.Aliases)
-			}
+			aliases = append(aliases, h.Aliases...)
 		}
 
-		primary.Aliases = fmt.Sprintf("(%s)", joinStrings(aliases))
+		primary.Aliases = aliases
 		hosts = append(hosts, primary)
 	}
 
 	return hosts, nil
 }
 
-func (h *Host) RunCmdTmpl(tmplstr string) error {
-	tmpl, err := template.New("command").Parse(tmplstr)
+// Args is a list of command arguments. It renders shell-quoted in templates so
+// each element survives splitting as a single argument.
+type Args []string
+
+func (a Args) String() string {
+	quoted := make([]string, 0, len(a))
+	for _, arg := range a {
+		quoted = append(quoted, shellQuote(arg))
+	}
+
+	return strings.Join(quoted, " ")
+}
+
+// shellQuote single quotes s if it contains anything a shell would interpret.
+func shellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+
+	if !strings.ContainsAny(s, " \t\n'\"\\$`|&;<>()*?[]#~!{}") {
+		return s
+	}
+
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// JumpHost is an ad hoc bastion to connect through. It renders as the ssh
+// `-J host` option in templates, or nothing when unset.
+type JumpHost string
+
+func (j JumpHost) String() string {
+	if j == "" {
+		return ""
+	}
+
+	return "-J " + shellQuote(string(j))
+}
+
+// Override replaces the configured user and port for a single connection. It
+// renders as the ssh `-l user -p port` options in templates, or nothing when
+// unset.
+type Override struct {
+	User string
+	Port string
+}
+
+func (o Override) String() string {
+	var opts []string
+	if o.User != "" {
+		opts = append(opts, "-l", o.User)
+	}
+
+	if o.Port != "" {
+		opts = append(opts, "-p", o.Port)
+	}
+
+	return Args(opts).String()
+}
+
+// Identity is a key chosen for a single connection. It renders as the ssh
+// `-i path` option in templates, or nothing when unset.
+type Identity string
+
+func (i Identity) String() string {
+	if i == "" {
+		return ""
+	}
+
+	return "-i " + shellQuote(string(i))
+}
+
+// RemoteCommand runs on the host instead of a login shell. It renders as a
+// single quoted argument, so the remote shell sees it as written, or nothing
+// when unset.
+type RemoteCommand string
+
+func (c RemoteCommand) String() string {
+	if c == "" {
+		return ""
+	}
+
+	return shellQuote(string(c))
+}
+
+// CmdVars holds per-connection values available to command templates in
+// addition to the Host fields.
+type CmdVars struct {
+	// ExtraArgs are additional arguments passed through from the CLI.
+	ExtraArgs Args
+	// Jump is a bastion host to connect through.
+	Jump JumpHost
+	// Override replaces the host's user and port for this connection.
+	Override Override
+	// Identity is the key to try first for this connection.
+	Identity Identity
+	// Command is run on the host instead of a shell.
+	Command RemoteCommand
+}
+
+// tmplData is what command templates are executed against, so both
+// {{.Name}} and {{.ExtraArgs}} resolve.
+type tmplData struct {
+	*Host
+	CmdVars
+}
+
+// ErrCommandNotFound is returned when a rendered command's binary is not on PATH.
+var ErrCommandNotFound = errors.New("command not found")
+
+// RenderCmd executes the command template against h and returns the command
+// line, normalised so each argument is separated by a single space and quoted
+// only where needed.
+func (h *Host) RenderCmd(tmplstr string, vars CmdVars) (string, error) {
+	parts, err := h.splitCmd(tmplstr, vars)
+	if err != nil {
+		return "", err
+	}
+
+	return Args(parts).String(), nil
+}
+
+// CmdArgs renders the command template and splits it into argv, checking that
+// the binary it runs can be found on PATH.
+func (h *Host) CmdArgs(tmplstr string, vars CmdVars) ([]string, error) {
+	parts, err := h.splitCmd(tmplstr, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := exec.LookPath(parts[0]); err != nil {
+		return nil, fmt.Errorf("%w: %q is not on PATH (check the command template)", ErrCommandNotFound, parts[0])
+	}
+
+	return parts, nil
+}
+
+// ValidateCmdTmpl reports whether tmplstr parses as a command template, to
+// catch mistakes before trying to connect.
+func ValidateCmdTmpl(tmplstr string) error {
+	if _, err := parseCmdTmpl(tmplstr); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func parseCmdTmpl(tmplstr string) (*template.Template, error) {
+	tmpl, err := template.New("command").Funcs(tmplFuncs).Parse(tmplstr)
 	if err != nil {
-		return fmt.Errorf("could not parse command template: %w", err)
+		return nil, fmt.Errorf("could not parse command template: %w", err)
+	}
+
+	return tmpl, nil
+}
+
+// splitCmd executes the command template against h and splits the result into
+// arguments.
+func (h *Host) splitCmd(tmplstr string, vars CmdVars) ([]string, error) {
+	tmpl, err := parseCmdTmpl(tmplstr)
+	if err != nil {
+		return nil, err
 	}
 
 	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, h); err != nil {
-		return fmt.Errorf("error executing command template: %w", err)
+	if err := tmpl.Execute(&buf, tmplData{Host: h, CmdVars: vars}); err != nil {
+		return nil, fmt.Errorf("error executing command template: %w", err)
 	}
 
-	commandLine := buf.String()
-	fmt.Printf("Running command: %s\n", commandLine)
+	parts, err := splitArgs(buf.String())
+	if err != nil {
+		return nil, fmt.Errorf("could not split command line: %w", err)
+	}
 
-	parts := strings.Fields(commandLine)
 	if len(parts) == 0 {
-		return errors.New("command is empty")
+		return nil, errors.New("command is empty")
 	}
 
-	cmd := exec.Command(parts[0], parts[1:]...)
+	return parts, nil
+}
+
+// Runner runs a command, such as the one rendered to connect to a host.
+type Runner interface {
+	Run(name string, args ...string) error
+}
+
+// execRunner runs commands attached to the terminal.
+type execRunner struct{}
+
+func (execRunner) Run(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
 
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
@@ -236,3 +1021,92 @@ func (h *Host) RunCmdTmpl(tmplstr string) error {
 
 	return cmd.Run()
 }
+
+func (h *Host) RunCmdTmpl(tmplstr string, vars CmdVars) error {
+	return h.RunCmdTmplWith(execRunner{}, tmplstr, vars)
+}
+
+// RunCmdTmplWith is RunCmdTmpl with the command run by r, or attached to the
+// terminal if r is nil.
+func (h *Host) RunCmdTmplWith(r Runner, tmplstr string, vars CmdVars) error {
+	parts, err := h.CmdArgs(tmplstr, vars)
+	if err != nil {
+		return err
+	}
+
+	if r == nil {
+		r = execRunner{}
+	}
+
+	fmt.Printf("Running command: %s\n", Args(parts))
+
+	return r.Run(parts[0], parts[1:]...)
+}
+
+// splitArgs splits a command line into arguments the way a POSIX shell would,
+// honouring single quotes, double quotes and backslash escapes so quoted
+// arguments (e.g. a remote command) are kept as a single element.
+func splitArgs(s string) ([]string, error) {
+	var (
+		args    []string
+		cur     strings.Builder
+		inArg   bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			// Inside double quotes a backslash only escapes a few characters.
+			if quote == '"' && !strings.ContainsRune(`"\$`+"`", r) {
+				cur.WriteRune('\\')
+			}
+
+			cur.WriteRune(r)
+
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+				continue
+			}
+
+			cur.WriteRune(r)
+		case r == '\\':
+			escaped = true
+			inArg = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+				continue
+			}
+
+			cur.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+
+				inArg = false
+			}
+		default:
+			cur.WriteRune(r)
+
+			inArg = true
+		}
+	}
+
+	if escaped || quote != 0 {
+		return nil, errors.New("unterminated quote or escape")
+	}
+
+	if inArg {
+		args = append(args, cur.String())
+	}
+
+	return args, nil
+}
@@ -0,0 +1,665 @@
+package ssh
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kevinburke/ssh_config"
+)
+
+func TestDisplayName(t *testing.T) {
+	tests := []struct {
+		name   string
+		suffix string
+		want   string
+	}{
+		{"web1.prod.example.com", "", "web1.prod.example.com"},
+		{"web1.prod.example.com", ".prod.example.com", "web1"},
+		{"web1.prod.example.com", ".example.com", "web1.prod"},
+		{"web1.dev.example.com", ".prod.example.com", "web1.dev.example.com"},
+		// Stripping the whole name would leave nothing to show
+		{".prod.example.com", ".prod.example.com", ".prod.example.com"},
+		{"prod.example.com", "prod.example.com", "prod.example.com"},
+	}
+
+	for _, tt := range tests {
+		h := &Host{Name: tt.name}
+		if got := h.DisplayName(tt.suffix); got != tt.want {
+			t.Errorf("DisplayName(%q) of %s = %q, want %q", tt.suffix, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestArgsString(t *testing.T) {
+	tests := []struct {
+		name string
+		args Args
+		want string
+	}{
+		{"plain", Args{"-v", "-L", "8080:localhost:80"}, "-v -L 8080:localhost:80"},
+		{"spaces", Args{"-o", "ProxyCommand=ssh bastion nc %h %p"}, "-o 'ProxyCommand=ssh bastion nc %h %p'"},
+		{"single quote", Args{"it's"}, `'it'\''s'`},
+		{"empty", Args{"-o", ""}, "-o ''"},
+		{"none", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.args.String()
+			if got != tt.want {
+				t.Fatalf("String() = %q, want %q", got, tt.want)
+			}
+
+			// Each element must survive being split back into argv.
+			split, err := splitArgs(got)
+			if err != nil {
+				t.Fatalf("splitArgs(%q): %v", got, err)
+			}
+
+			if !slices.Equal(split, []string(tt.args)) && len(tt.args) > 0 {
+				t.Errorf("splitArgs(%q) = %q, want %q", got, split, tt.args)
+			}
+		})
+	}
+}
+
+// decodeHost parses a single host block from config.
+func decodeHost(t *testing.T, config string) *ssh_config.Host {
+	t.Helper()
+
+	cfg, err := ssh_config.Decode(strings.NewReader(config))
+	if err != nil {
+		t.Fatalf("decode config: %v", err)
+	}
+
+	// The first block is the implicit "Host *" holding anything before the
+	// first Host line.
+	if len(cfg.Hosts) != 2 {
+		t.Fatalf("decoded %d host blocks, want 2", len(cfg.Hosts))
+	}
+
+	return cfg.Hosts[1]
+}
+
+func TestExplodeHost(t *testing.T) {
+	t.Run("shared hostname", func(t *testing.T) {
+		hosts := explodeHost(decodeHost(t, "Host web1 web2 *\n  HostName 10.0.0.1\n  User deploy\n"))
+
+		var names []string
+		for _, h := range hosts {
+			names = append(names, h.Name)
+
+			if h.Hostname != "10.0.0.1" || h.User != "deploy" {
+				t.Errorf("%s: Hostname %q, User %q, want the block's options", h.Name, h.Hostname, h.User)
+			}
+
+			if len(h.Aliases) != 0 {
+				t.Errorf("%s: Aliases = %q, want none", h.Name, h.Aliases)
+			}
+		}
+
+		if want := []string{"web1", "web2"}; !slices.Equal(names, want) {
+			t.Errorf("names = %q, want %q", names, want)
+		}
+	})
+
+	t.Run("negated pattern", func(t *testing.T) {
+		hosts := explodeHost(decodeHost(t, "Host web* !web-old db1\n  User deploy\n"))
+
+		var names []string
+		for _, h := range hosts {
+			names = append(names, h.Name)
+		}
+
+		if want := []string{"web*", "db1"}; !slices.Equal(names, want) {
+			t.Errorf("names = %q, want %q", names, want)
+		}
+	})
+
+	t.Run("pattern as hostname", func(t *testing.T) {
+		hosts := explodeHost(decodeHost(t, "Host db1 db2\n  User admin\n"))
+		if len(hosts) != 2 {
+			t.Fatalf("got %d hosts, want 2", len(hosts))
+		}
+
+		for _, h := range hosts {
+			if h.Hostname != h.Name {
+				t.Errorf("%s: Hostname = %q, want the pattern", h.Name, h.Hostname)
+			}
+		}
+	})
+}
+
+// loadConfig loads hosts from config written to a temporary file.
+func loadConfig(t *testing.T, config string) []*Host {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	hosts, err := LoadSSHConfig([]string{path}, LoadOptions{})
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+
+	return hosts
+}
+
+func TestAliases(t *testing.T) {
+	hosts := loadConfig(t, "Host web1 w1\n  HostName 10.0.0.1\nHost web2\n  HostName 10.0.0.1\n")
+	if len(hosts) != 1 {
+		t.Fatalf("got %d hosts, want the two grouped by hostname", len(hosts))
+	}
+
+	h := hosts[0]
+	if want := []string{"web2", "w1"}; h.Name != "web1" || !slices.Equal(h.Aliases, want) {
+		t.Errorf("got %s with aliases %q, want web1 with %q", h.Name, h.Aliases, want)
+	}
+
+	if got, want := h.DisplayAliases(""), "(web2, w1)"; got != want {
+		t.Errorf("DisplayAliases() = %q, want %q", got, want)
+	}
+
+	if got := (&Host{Name: "db1"}).DisplayAliases(""); got != "" {
+		t.Errorf("DisplayAliases() with none = %q, want blank", got)
+	}
+
+	for format, want := range map[AliasFormat]string{AliasParen: "(web2, w1)", AliasComma: "web2, w1", AliasHidden: ""} {
+		if got := h.DisplayAliases(format); got != want {
+			t.Errorf("DisplayAliases(%q) = %q, want %q", format, got, want)
+		}
+	}
+}
+
+func TestParseAliasFormat(t *testing.T) {
+	for _, s := range []string{"paren", "comma", "hidden"} {
+		if f, err := ParseAliasFormat(s); err != nil || string(f) != s {
+			t.Errorf("ParseAliasFormat(%q) = %q, %v", s, f, err)
+		}
+	}
+
+	if _, err := ParseAliasFormat("brackets"); err == nil {
+		t.Error("ParseAliasFormat(brackets) succeeded, want an error")
+	}
+}
+
+func TestRetriesAnnotation(t *testing.T) {
+	tests := []struct {
+		name    string
+		comment string
+		want    int
+	}{
+		{"set", "#retries: 3", 3},
+		{"spaced and capitalised", "# Retries : 5", 5},
+		{"not a number", "#retries: lots", 0},
+		{"negative", "#retries: -1", 0},
+		{"other comment", "# web server", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHost(decodeHost(t, "Host web1\n  "+tt.comment+"\n  HostName 10.0.0.1\n"))
+			if h.Retries != tt.want {
+				t.Errorf("Retries = %d, want %d", h.Retries, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetEnv(t *testing.T) {
+	h := NewHost(decodeHost(t, `Host web1
+  SetEnv LANG=en_GB.UTF-8 GREETING="hello there"
+  SetEnv LANG=C EDITOR=vim bad
+`))
+
+	want := map[string]string{"LANG": "en_GB.UTF-8", "GREETING": "hello there", "EDITOR": "vim"}
+	if !maps.Equal(h.SetEnv, want) {
+		t.Errorf("SetEnv = %v, want %v", h.SetEnv, want)
+	}
+
+	got, err := h.splitCmd("ssh {{.SetEnvOpts}} {{.Name}}", CmdVars{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantArgs := []string{"ssh", "-o", "SetEnv=EDITOR=vim GREETING='hello there' LANG=en_GB.UTF-8", "web1"}
+	if !slices.Equal(got, wantArgs) {
+		t.Errorf("splitCmd = %q, want %q", got, wantArgs)
+	}
+
+	if opts := (&Host{Name: "db1"}).SetEnvOpts(); opts != nil {
+		t.Errorf("SetEnvOpts() with no SetEnv = %q, want nil", opts)
+	}
+}
+
+func TestResolveEffective(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		alias  string
+		key    string
+		want   string
+	}{
+		{
+			name:   "specific block first",
+			config: "Host web1\n  User alice\nHost *\n  User everyone\n  Port 2222\n",
+			alias:  "web1", key: "user", want: "alice",
+		},
+		{
+			name:   "inherited from Host *",
+			config: "Host web1\n  User alice\nHost *\n  User everyone\n  Port 2222\n",
+			alias:  "web1", key: "port", want: "2222",
+		},
+		{
+			name:   "Host * first",
+			config: "Host *\n  User everyone\nHost web1\n  User alice\n",
+			alias:  "web1", key: "user", want: "everyone",
+		},
+		{
+			name:   "other host",
+			config: "Host web1\n  User alice\nHost *\n  User everyone\n",
+			alias:  "db1", key: "user", want: "everyone",
+		},
+		{
+			name:   "negated",
+			config: "Host * !web1\n  User everyone\n",
+			alias:  "web1", key: "user", want: "",
+		},
+		{
+			name:   "wildcard",
+			config: "Host web*\n  Port 2200\nHost web1\n  Port 22\n",
+			alias:  "web1", key: "port", want: "2200",
+		},
+		{
+			name:   "identity, specific block first",
+			config: "Host web1\n  IdentityFile ~/.ssh/web\nHost *\n  IdentityFile ~/.ssh/default\n",
+			alias:  "web1", key: "identityfile", want: "~/.ssh/web",
+		},
+		{
+			name:   "identity, Host * first",
+			config: "Host *\n  IdentityFile ~/.ssh/default\nHost web1\n  IdentityFile ~/.ssh/web\n",
+			alias:  "web1", key: "identityfile", want: "~/.ssh/default",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := ssh_config.Decode(strings.NewReader(tt.config))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := resolveEffective(cfg.Hosts, tt.alias, tt.key); got != tt.want {
+				t.Errorf("resolveEffective(%s, %s) = %q, want %q", tt.alias, tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadResolveEffectiveIdentity(t *testing.T) {
+	t.Setenv("HOME", "/home/me")
+
+	path := filepath.Join(t.TempDir(), "config")
+
+	const config = "Host web1\n  HostName 10.0.0.1\n\nHost db1\n  HostName 10.0.0.2\n  IdentityFile ~/.ssh/db\n\nHost *\n  IdentityFile ~/.ssh/default\n"
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	hosts, err := LoadSSHConfig([]string{path}, LoadOptions{ResolveEffective: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]string{
+		"web1": {"/home/me/.ssh/default"},
+		"db1":  {"/home/me/.ssh/db", "/home/me/.ssh/default"},
+	}
+
+	seen := 0
+
+	for _, h := range hosts {
+		w, ok := want[h.Name]
+		if !ok {
+			continue
+		}
+
+		seen++
+
+		if h.IdentityFile != w[0] || !slices.Equal(h.IdentityFiles, w) {
+			t.Errorf("%s: IdentityFile %q, IdentityFiles %q, want %q", h.Name, h.IdentityFile, h.IdentityFiles, w)
+		}
+	}
+
+	if seen != len(want) {
+		t.Errorf("loaded %d of web1 and db1", seen)
+	}
+}
+
+func TestSplitHostPort(t *testing.T) {
+	tests := []struct {
+		name     string
+		hostname string
+		port     string
+		wantHost string
+		wantPort string
+	}{
+		{"plain", "example.com", "", "example.com", ""},
+		{"embedded port", "example.com:2222", "", "example.com", "2222"},
+		{"Port option wins", "example.com:2222", "22", "example.com", "22"},
+		{"bracketed IPv6", "[2001:db8::1]:2222", "", "2001:db8::1", "2222"},
+		{"bracketed IPv6 without port", "[2001:db8::1]", "", "2001:db8::1", ""},
+		{"bare IPv6", "2001:db8::1", "", "2001:db8::1", ""},
+		{"not a port", "example.com:ssh", "", "example.com:ssh", ""},
+		{"unclosed bracket", "[2001:db8::1", "2222", "[2001:db8::1", "2222"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, port := splitHostPort(tt.hostname, tt.port)
+			if host != tt.wantHost || port != tt.wantPort {
+				t.Errorf("splitHostPort(%q, %q) = %q, %q, want %q, %q",
+					tt.hostname, tt.port, host, port, tt.wantHost, tt.wantPort)
+			}
+		})
+	}
+
+	h := NewHost(decodeHost(t, "Host web1\n  HostName web1.example.com:2222\n  Port 2200\n"))
+	if h.Hostname != "web1.example.com" || h.Port != "2200" {
+		t.Errorf("NewHost: Hostname %q, Port %q, want the Port option over the inline port", h.Hostname, h.Port)
+	}
+}
+
+func TestLoadSSHConfigInclude(t *testing.T) {
+	hosts, err := LoadSSHConfig([]string{"../testfiles/include_config"}, LoadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name, user, hostname, source string
+		line                         int
+	}{
+		// Included hosts come where the Include is, before main
+		{"alpha", "alpha", "10.0.1.2", "../testfiles/config.d/alpha", 1},
+		{"beta", "beta", "10.0.1.3", "../testfiles/config.d/beta", 1},
+		{"main", "main", "10.0.1.1", "../testfiles/include_config", 3},
+	}
+
+	if len(hosts) != len(tests) {
+		t.Fatalf("loaded %d hosts, want %d", len(hosts), len(tests))
+	}
+
+	for i, tt := range tests {
+		h := hosts[i]
+		if h.Name != tt.name || h.User != tt.user || h.Hostname != tt.hostname {
+			t.Errorf("host %d = %s (%s@%s), want %s (%s@%s)", i, h.Name, h.User, h.Hostname, tt.name, tt.user, tt.hostname)
+		}
+
+		if source, _ := filepath.Abs(tt.source); h.SourceFile != source || h.SourceLine != tt.line {
+			t.Errorf("%s defined at %s:%d, want %s:%d", h.Name, h.SourceFile, h.SourceLine, source, tt.line)
+		}
+	}
+}
+
+func TestLoadSSHConfigIncludeProblems(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, config string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		return path
+	}
+
+	write("loop", "Include config\n\nHost looped\n  Hostname 10.0.0.2\n")
+	config := write("config", "Include loop\nInclude missing\nInclude conf.d/*\n\nHost web1\n  Hostname 10.0.0.1\n")
+
+	var diags []string
+
+	hosts, err := LoadSSHConfig([]string{config}, LoadOptions{Warn: func(d Diagnostic) { diags = append(diags, d.Message) }})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, h := range hosts {
+		names = append(names, h.Name)
+	}
+
+	if want := []string{"looped", "web1"}; !slices.Equal(names, want) {
+		t.Errorf("loaded %q, want %q", names, want)
+	}
+
+	// An empty glob isn't worth a warning, but a missing file is.
+	want := []string{
+		"skipping circular include of " + config,
+		"included file " + filepath.Join(dir, "missing") + " does not exist",
+	}
+	if !slices.Equal(diags, want) {
+		t.Errorf("warnings = %q, want %q", diags, want)
+	}
+}
+
+func TestIsPattern(t *testing.T) {
+	for name, want := range map[string]bool{
+		"web1":       false,
+		"*.internal": true,
+		"web-?":      true,
+		"!db2":       true,
+	} {
+		if got := (&Host{Name: name}).IsPattern(); got != want {
+			t.Errorf("IsPattern(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestIdentityFiles(t *testing.T) {
+	t.Setenv("HOME", "/home/me")
+
+	tests := []struct {
+		config string
+		want   []string
+	}{
+		{"Host web1\n", nil},
+		{"Host web1\n  IdentityFile ~/.ssh/web\n", []string{"/home/me/.ssh/web"}},
+		{"Host web1\n  IdentityFile ~/.ssh/web\n  IdentityFile /etc/ssh/deploy\n", []string{"/home/me/.ssh/web", "/etc/ssh/deploy"}},
+	}
+
+	for _, tt := range tests {
+		h := NewHost(decodeHost(t, tt.config))
+
+		if !slices.Equal(h.IdentityFiles, tt.want) {
+			t.Errorf("%q: IdentityFiles = %q, want %q", tt.config, h.IdentityFiles, tt.want)
+		}
+
+		var first string
+		if len(tt.want) > 0 {
+			first = tt.want[0]
+		}
+
+		if h.IdentityFile != first {
+			t.Errorf("%q: IdentityFile = %q, want %q", tt.config, h.IdentityFile, first)
+		}
+	}
+}
+
+func TestHash(t *testing.T) {
+	byName := func(config string) map[string]string {
+		hashes := map[string]string{}
+		for _, h := range loadConfig(t, config) {
+			hashes[h.Name] = h.Hash()
+		}
+
+		return hashes
+	}
+
+	before := byName("Host web1\n  HostName 10.0.0.1\n\nHost db1\n  HostName 10.0.0.2\n")
+	after := byName("Host web1\n  HostName 10.0.0.9\n\nHost db1\n  HostName 10.0.0.2\n")
+
+	if before["web1"] == after["web1"] {
+		t.Error("web1's hash didn't change with its block")
+	}
+
+	if before["db1"] != after["db1"] {
+		t.Error("db1's hash changed though its block didn't")
+	}
+
+	if got := (&Host{Name: "scratch"}).Hash(); got != "" {
+		t.Errorf("hash of a host without a block = %q, want empty", got)
+	}
+}
+
+func TestNewHostRepeatedOptions(t *testing.T) {
+	host := NewHost(decodeHost(t, `Host web1
+  Hostname 10.0.0.1
+  LocalForward 8080 localhost:80
+  IdentityFile /keys/id_ed25519
+  LocalForward 8443 localhost:443
+  DynamicForward 1080
+  localforward 5432 db:5432
+  IdentityFile /keys/id_rsa
+  SetEnv LANG=C TERM=xterm
+  SetEnv LANG=en_GB.UTF-8 EDITOR="vim -u NONE"
+`))
+
+	wantForwards := []string{
+		"LocalForward 8080 localhost:80",
+		"LocalForward 8443 localhost:443",
+		"LocalForward 5432 db:5432",
+		"DynamicForward 1080",
+	}
+	if !slices.Equal(host.Forwards, wantForwards) {
+		t.Errorf("Forwards = %q, want %q", host.Forwards, wantForwards)
+	}
+
+	wantKeys := []string{"/keys/id_ed25519", "/keys/id_rsa"}
+	if !slices.Equal(host.IdentityFiles, wantKeys) {
+		t.Errorf("IdentityFiles = %q, want %q", host.IdentityFiles, wantKeys)
+	}
+
+	wantEnv := map[string]string{"LANG": "C", "TERM": "xterm", "EDITOR": "vim -u NONE"}
+	if !maps.Equal(host.SetEnv, wantEnv) {
+		t.Errorf("SetEnv = %v, want %v", host.SetEnv, wantEnv)
+	}
+}
+
+func TestGetOptVals(t *testing.T) {
+	block := decodeHost(t, `Host web1
+  User bob
+  LocalForward 8080 localhost:80
+  LocalForward 8443 localhost:443
+`)
+
+	tests := []struct {
+		opt  string
+		want []string
+	}{
+		{"LocalForward", []string{"8080 localhost:80", "8443 localhost:443"}},
+		{"localforward", []string{"8080 localhost:80", "8443 localhost:443"}},
+		{"User", []string{"bob"}},
+		{"RemoteForward", nil},
+	}
+
+	for _, tt := range tests {
+		if got := getOptVals(block, tt.opt); !slices.Equal(got, tt.want) {
+			t.Errorf("getOptVals(%q) = %q, want %q", tt.opt, got, tt.want)
+		}
+	}
+}
+
+func TestConnectFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		comment string
+		want    []string
+	}{
+		{"none", "# web server", nil},
+		{"flags", "#pssh: --retry-max 2 --retry-delay 10s", []string{"--retry-max", "2", "--retry-delay", "10s"}},
+		{"quoted", `#pssh: --command "tail -f /var/log/syslog"`, []string{"--command", "tail -f /var/log/syslog"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHost(decodeHost(t, "Host web1\n  "+tt.comment+"\n  HostName 10.0.0.1\n"))
+
+			got, err := h.ConnectFlags()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ConnectFlags() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	h := NewHost(decodeHost(t, "Host web1\n  #pssh: --command \"unclosed\n"))
+	if _, err := h.ConnectFlags(); err == nil {
+		t.Error("ConnectFlags() with an unclosed quote succeeded")
+	}
+}
+
+func TestLoadWarnings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+
+	const config = "Host web1\n  HostName 10.0.0.1\n  Prot 22\n\nHost web1\n  User root\n"
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var warnings []string
+
+	opts := LoadOptions{Warn: func(d Diagnostic) { warnings = append(warnings, d.String()) }}
+	if _, err := LoadSSHConfig([]string{path}, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		path + `:3: unknown option "Prot"`,
+		fmt.Sprintf("%s:5: host web1 is already defined at %s:1", path, path),
+	}
+	if !slices.Equal(warnings, want) {
+		t.Errorf("warnings = %q, want %q", warnings, want)
+	}
+
+	// With Strict, unknown options fail loading instead
+	opts.Strict = true
+	if _, err := LoadSSHConfig([]string{path}, opts); err == nil {
+		t.Error("loading with Strict succeeded despite an unknown option")
+	}
+}
+
+func TestLoadMissingConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "config")
+	if err := os.WriteFile(path, []byte("Host web1\n  HostName 10.0.0.1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	missing := filepath.Join(dir, "missing")
+
+	var warnings []string
+
+	hosts, err := LoadSSHConfig([]string{missing, path}, LoadOptions{Warn: func(d Diagnostic) { warnings = append(warnings, d.String()) }})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(hosts) != 1 || hosts[0].Name != "web1" {
+		t.Errorf("loaded %d hosts, want only web1", len(hosts))
+	}
+
+	if want := []string{missing + ": skipping missing ssh config file"}; !slices.Equal(warnings, want) {
+		t.Errorf("warnings = %q, want %q", warnings, want)
+	}
+}
@@ -0,0 +1,93 @@
+// Package state persists small bits of pssh state between runs
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+type State struct {
+	// RecentQueries are the most recent TUI search queries, oldest first.
+	RecentQueries []string `json:"recent_queries,omitempty"`
+	// LastHost is the name of the host most recently selected in the TUI.
+	LastHost string `json:"last_host,omitempty"`
+	// HostHashes are the hashes of each host's config block as of the last
+	// run, for spotting changed hosts.
+	HostHashes map[string]string `json:"host_hashes,omitempty"`
+}
+
+// Dir returns the directory pssh keeps its state in, honouring
+// $XDG_STATE_HOME.
+func Dir() (string, error) {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("could not get user home directory: %w", err)
+		}
+
+		dir = filepath.Join(home, ".local", "state")
+	}
+
+	return filepath.Join(dir, "pssh"), nil
+}
+
+// Path returns the location of the state file.
+func Path() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "state.json"), nil
+}
+
+// Load reads the state file. A missing file yields an empty State.
+func Load() (*State, error) {
+	fp, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Clean(fp))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &State{}, nil
+		}
+
+		return nil, fmt.Errorf("could not read state file %s: %w", fp, err)
+	}
+
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("could not decode state file %s: %w", fp, err)
+	}
+
+	return &s, nil
+}
+
+// Save writes the state file, creating its directory if needed.
+func (s *State) Save() error {
+	fp, err := Path()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fp), 0o700); err != nil {
+		return fmt.Errorf("could not create state directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode state: %w", err)
+	}
+
+	if err := os.WriteFile(fp, data, 0o600); err != nil {
+		return fmt.Errorf("could not write state file %s: %w", fp, err)
+	}
+
+	return nil
+}
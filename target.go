@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/sahilm/fuzzy"
+
+	"github.com/pix-xip/pssh/ssh"
+)
+
+// parseTarget splits a CLI target of the form [ssh://][user@]name[:port] into
+// its parts. User and port are empty when not given.
+func parseTarget(s string) (name, user, port string) {
+	if strings.HasPrefix(s, "ssh://") {
+		if u, err := url.Parse(s); err == nil {
+			return u.Hostname(), u.User.Username(), u.Port()
+		}
+
+		s = strings.TrimPrefix(s, "ssh://")
+	}
+
+	if i := strings.LastIndex(s, "@"); i >= 0 {
+		user, s = s[:i], s[i+1:]
+	}
+
+	name = s
+	if strings.Contains(s, ":") {
+		// SplitHostPort copes with [ipv6]:port and rejects a bare ipv6
+		// address, which is left as the name.
+		if h, p, err := net.SplitHostPort(s); err == nil {
+			name, port = h, p
+		}
+	}
+
+	return name, user, port
+}
+
+// resolveTarget looks up the host named by target, by name or alias, among the
+// loaded hosts, returning the user/port override it specifies.
+func resolveTarget(target string, loadHosts func() ([]*ssh.Host, error)) (*ssh.Host, ssh.Override, error) {
+	name, user, port := parseTarget(target)
+
+	hosts, err := loadHosts()
+	if err != nil {
+		return nil, ssh.Override{}, err
+	}
+
+	if found := findHosts(hosts, name); len(found) > 0 {
+		return found[0], ssh.Override{User: user, Port: port}, nil
+	}
+
+	return nil, ssh.Override{}, fmt.Errorf("host %q not found in ssh config", name)
+}
+
+// scratchHost builds a host for a target not in any config, e.g.
+// user@1.2.3.4:2222, along with the override connecting as its user and port.
+func scratchHost(target string) (*ssh.Host, ssh.Override) {
+	name, user, port := parseTarget(target)
+
+	host := &ssh.Host{
+		Name:     name,
+		User:     user,
+		Hostname: name,
+		Port:     port,
+	}
+
+	return host, ssh.Override{User: user, Port: port}
+}
+
+// findHosts returns every host with the given name or alias.
+func findHosts(hosts []*ssh.Host, name string) []*ssh.Host {
+	var found []*ssh.Host
+
+	for _, h := range hosts {
+		if h.Name == name || slices.Contains(h.Aliases, name) {
+			found = append(found, h)
+		}
+	}
+
+	return found
+}
+
+// matchesAny reports whether term fuzzy matches the name, aliases or
+// hostname of any host, as a search in the TUI would.
+func matchesAny(hosts []*ssh.Host, term string) bool {
+	targets := make([]string, len(hosts))
+	for i, h := range hosts {
+		targets[i] = strings.Join(append([]string{h.Name, h.Hostname}, h.Aliases...), " ")
+	}
+
+	return len(fuzzy.Find(term, targets)) > 0
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/pix-xip/pssh/ssh"
+)
+
+func TestFindHosts(t *testing.T) {
+	hosts := []*ssh.Host{
+		{Name: "web1", Aliases: []string{"www"}},
+		{Name: "web2", Aliases: []string{"www"}},
+		{Name: "db1"},
+	}
+
+	for name, want := range map[string][]*ssh.Host{
+		"db1": {hosts[2]},
+		"www": {hosts[0], hosts[1]},
+		"web": nil,
+	} {
+		if got := findHosts(hosts, name); !slices.Equal(got, want) {
+			t.Errorf("findHosts(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestMatchesAny(t *testing.T) {
+	hosts := []*ssh.Host{
+		{Name: "web1", Hostname: "10.0.0.1", Aliases: []string{"frontend"}},
+		{Name: "db1", Hostname: "db.prod.example.com"},
+	}
+
+	for term, want := range map[string]bool{
+		"web":   true,
+		"front": true,
+		"prod":  true,
+		"cache": false,
+	} {
+		if got := matchesAny(hosts, term); got != want {
+			t.Errorf("matchesAny(%q) = %v, want %v", term, got, want)
+		}
+	}
+}
+
+func TestResolveTarget(t *testing.T) {
+	hosts := []*ssh.Host{
+		{Name: "bestie", Aliases: []string{"extra-bestie", "bff"}},
+		{Name: "web1"},
+	}
+	load := func() ([]*ssh.Host, error) { return hosts, nil }
+
+	tests := []struct {
+		target   string
+		want     string
+		override ssh.Override
+		wantErr  bool
+	}{
+		{target: "web1", want: "web1"},
+		{target: "extra-bestie", want: "bestie"},
+		{target: "bob@bff:2222", want: "bestie", override: ssh.Override{User: "bob", Port: "2222"}},
+		{target: "ssh://alice@web1", want: "web1", override: ssh.Override{User: "alice"}},
+		{target: "web2", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			host, override, err := resolveTarget(tt.target, load)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveTarget(%q) error = %v, wantErr %v", tt.target, err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if host.Name != tt.want {
+				t.Errorf("resolveTarget(%q) = %s, want %s", tt.target, host.Name, tt.want)
+			}
+
+			if override != tt.override {
+				t.Errorf("resolveTarget(%q) override = %+v, want %+v", tt.target, override, tt.override)
+			}
+		})
+	}
+}
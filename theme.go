@@ -0,0 +1,20 @@
+package main
+
+import (
+	"github.com/pix-xip/pssh/config"
+	"github.com/pix-xip/pssh/tui"
+)
+
+// loadTheme reads the TUI's colours from the config file.
+func loadTheme() (tui.Theme, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return tui.Theme{}, err
+	}
+
+	return tui.Theme{
+		Border:             cfg.Theme.Border,
+		SelectedForeground: cfg.Theme.SelectedForeground,
+		SelectedBackground: cfg.Theme.SelectedBackground,
+	}, nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pix-xip/pssh/tui"
+)
+
+func TestLoadTheme(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	path := filepath.Join(dir, "pssh", "config.toml")
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	write := func(config string) {
+		if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("[theme]\nborder = \"240\"\n")
+
+	if got, err := loadTheme(); err != nil || got != (tui.Theme{Border: "240"}) {
+		t.Fatalf("loadTheme = %+v, %v", got, err)
+	}
+
+	// Each call rereads the file, so ctrl+l picks up edits
+	write("[theme]\nselected_background = \"#ff00ff\"\n")
+
+	if got, err := loadTheme(); err != nil || got != (tui.Theme{SelectedBackground: "#ff00ff"}) {
+		t.Errorf("loadTheme after editing = %+v, %v", got, err)
+	}
+}
@@ -0,0 +1,113 @@
+package tui
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pix-xip/pssh/ssh"
+	"github.com/sahilm/fuzzy"
+)
+
+// matchedAlias returns the alias of host which best matches query, or "" if
+// the name matches at least as well.
+func matchedAlias(query string, host *ssh.Host) string {
+	if query == "" || len(host.Aliases) == 0 {
+		return ""
+	}
+
+	names := append([]string{host.Name}, host.Aliases...)
+
+	// Matches are stably sorted by score, so the name wins a tie.
+	ranks := fuzzy.Find(query, names)
+	if len(ranks) == 0 || ranks[0].Index == 0 {
+		return ""
+	}
+
+	return names[ranks[0].Index]
+}
+
+// connectAs returns host to connect to by the alias matching query, so ssh
+// resolves the alias, or host itself if the name matched best.
+func connectAs(query string, host *ssh.Host) *ssh.Host {
+	alias := matchedAlias(query, host)
+	if alias == "" {
+		return host
+	}
+
+	return connectBy(host, alias)
+}
+
+// connectBy returns host to connect to by name, one of its aliases, so ssh
+// resolves that alias.
+func connectBy(host *ssh.Host, name string) *ssh.Host {
+	h := *host
+	h.Name = name
+
+	return &h
+}
+
+// aliasPicker chooses which of the names of a host, grouped with others
+// sharing its hostname, to connect by.
+type aliasPicker struct {
+	host   *ssh.Host
+	names  []string
+	cursor int
+}
+
+// openAliasPicker opens the picker for the host under the cursor, if it has
+// aliases to choose from.
+func (m *Model) openAliasPicker() tea.Cmd {
+	host := m.cursorHost()
+	if host == nil {
+		return nil
+	}
+
+	if len(host.Aliases) == 0 {
+		return m.setStatus(host.Name+" has no aliases to choose from", defaultStatusTTL)
+	}
+
+	m.aliasPicker = &aliasPicker{host: host, names: append([]string{host.Name}, host.Aliases...)}
+
+	return nil
+}
+
+// updateAliasPicker handles keys while the alias picker is open. Choosing a
+// name selects the host, connecting by that name.
+func (m Model) updateAliasPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.aliasPicker
+
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		m.aliasPicker = nil
+	case "up", "k":
+		p.cursor = max(p.cursor-1, 0)
+	case "down", "j":
+		p.cursor = min(p.cursor+1, len(p.names)-1)
+	case "enter":
+		m.alias = p.names[p.cursor]
+		m.aliasPicker = nil
+
+		return m.selectHost(p.host)
+	}
+
+	return m, nil
+}
+
+func (p *aliasPicker) View() string {
+	lines := []string{fmt.Sprintf("Connect to %s as:", cmp.Or(p.host.Hostname, p.host.Name)), ""}
+
+	for i, name := range p.names {
+		prefix := "  "
+		if i == p.cursor {
+			prefix = "> "
+		}
+
+		lines = append(lines, prefix+name)
+	}
+
+	lines = append(lines, "", "enter to connect • esc to cancel")
+
+	return pickerStyle.Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,85 @@
+package tui
+
+import (
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pix-xip/pssh/ssh"
+)
+
+func TestConnectAs(t *testing.T) {
+	host := &ssh.Host{Name: "web1", Aliases: []string{"www-public", "frontend"}}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", "web1"},
+		{"web1", "web1"},
+		{"front", "frontend"},
+		{"wwwpub", "www-public"},
+		{"zzz", "web1"},
+	}
+
+	for _, tt := range tests {
+		got := connectAs(tt.query, host)
+		if got.Name != tt.want {
+			t.Errorf("connectAs(%q) = %s, want %s", tt.query, got.Name, tt.want)
+		}
+	}
+
+	if host.Name != "web1" {
+		t.Errorf("connectAs changed the host's name to %s", host.Name)
+	}
+
+	if got := connectAs("web", &ssh.Host{Name: "web1"}); got.Name != "web1" {
+		t.Errorf("connectAs without aliases = %s, want web1", got.Name)
+	}
+}
+
+func TestAliasPicker(t *testing.T) {
+	m := configModel(t, Options{}, "Host web1\n  HostName 10.0.0.1\nHost www\n  HostName 10.0.0.1\nHost db1\n  HostName 10.0.0.2\n")
+	m.textInput.SetValue("10.0.0.1")
+	m.refilter()
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a"), Alt: true})
+	m = next.(Model)
+
+	if m.aliasPicker == nil {
+		t.Fatal("alt+a did not open the alias picker")
+	}
+
+	names := m.aliasPicker.names
+	if got := slices.Sorted(slices.Values(names)); !slices.Equal(got, []string{"web1", "www"}) {
+		t.Fatalf("picker names = %q, want web1 and www", names)
+	}
+
+	for _, key := range []tea.KeyType{tea.KeyDown, tea.KeyEnter} {
+		next, _ = m.Update(tea.KeyMsg{Type: key})
+		m = next.(Model)
+	}
+
+	if m.aliasPicker != nil {
+		t.Error("picker still open after enter")
+	}
+
+	if m.selectedHost == nil || m.selectedHost.Name != names[1] {
+		t.Errorf("selected %v, want to connect by %s", m.selectedHost, names[1])
+	}
+}
+
+func TestAliasPickerNoAliases(t *testing.T) {
+	m := configModel(t, Options{}, "Host db1\n  HostName 10.0.0.2\n")
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a"), Alt: true})
+	m = next.(Model)
+
+	if m.aliasPicker != nil {
+		t.Error("alias picker opened for a host without aliases")
+	}
+
+	if want := "db1 has no aliases to choose from"; m.status != want {
+		t.Errorf("status = %q, want %q", m.status, want)
+	}
+}
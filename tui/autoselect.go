@@ -0,0 +1,41 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// autoSelectDelay is how long the search must match a single host before it's
+// selected, so hosts matched briefly while typing aren't connected to.
+const autoSelectDelay = 500 * time.Millisecond
+
+// autoSelectMsg fires autoSelectDelay after query matched a single host.
+type autoSelectMsg struct {
+	query string
+}
+
+// scheduleAutoSelect returns a tick to select the only host matching the
+// current query if auto-selecting is enabled.
+func (m Model) scheduleAutoSelect() tea.Cmd {
+	query := m.textInput.Value()
+	if !m.opts.AutoSelect || query == "" || len(m.filteredHosts) != 1 {
+		return nil
+	}
+
+	return tea.Tick(autoSelectDelay, func(time.Time) tea.Msg {
+		return autoSelectMsg{query: query}
+	})
+}
+
+// autoSelect selects the only host matching the query, unless the search has
+// been edited since it was scheduled or a prompt has been opened.
+func (m Model) autoSelect(msg autoSelectMsg) (tea.Model, tea.Cmd) {
+	busy := m.picker != nil || m.aliasPicker != nil || m.login != nil || m.options != nil ||
+		m.diagnostics != nil || m.confirmingAll || m.columnsMode
+	if busy || m.textInput.Value() != msg.query || len(m.filteredHosts) != 1 {
+		return m, nil
+	}
+
+	return m.selectHost(m.filteredHosts[0])
+}
@@ -0,0 +1,62 @@
+package tui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pix-xip/pssh/ssh"
+)
+
+// confirmRunAll asks to run the command on every host shown in the table. Only
+// a command can be run on many hosts at once, as there's just the one
+// terminal.
+func (m *Model) confirmRunAll() tea.Cmd {
+	if m.opts.Vars.Command == "" {
+		return m.setStatus("set --command to run it on every host", defaultStatusTTL)
+	}
+
+	if len(m.shownHosts()) == 0 {
+		return m.setStatus("no hosts match", defaultStatusTTL)
+	}
+
+	m.confirmingAll = true
+
+	return nil
+}
+
+// updateConfirmAll handles the answer to confirmRunAll.
+func (m Model) updateConfirmAll(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.confirmingAll = false
+
+	if msg.String() != "y" {
+		return m, m.setStatus("cancelled", defaultStatusTTL)
+	}
+
+	m.runOnAll = m.shownHosts()
+	m.history.add(m.textInput.Value())
+	m.quitting = true
+
+	return m, tea.Quit
+}
+
+func (m Model) confirmAllPrompt() string {
+	n := len(m.shownHosts())
+	if m.moreHosts > 0 {
+		return fmt.Sprintf("Run %s on the %d hosts shown of %d matched? [y/N]", m.opts.Vars.Command, n, n+m.moreHosts)
+	}
+
+	return fmt.Sprintf("Run %s on %d hosts? [y/N]", m.opts.Vars.Command, n)
+}
+
+// shownHosts returns the hosts in the table's rows, leaving out group headers
+// and any filtered hosts past MaxHosts.
+func (m Model) shownHosts() []*ssh.Host {
+	hosts := make([]*ssh.Host, 0, len(m.rowHosts))
+	for _, h := range m.rowHosts {
+		if h != nil {
+			hosts = append(hosts, h)
+		}
+	}
+
+	return hosts
+}
@@ -0,0 +1,98 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pix-xip/pssh/ssh"
+)
+
+func TestRunOnAll(t *testing.T) {
+	const config = "Host web1\n  HostName 10.0.0.1\nHost web2\n  HostName 10.0.0.2\nHost db1\n  HostName 10.0.0.3\n"
+
+	m := configModel(t, Options{Vars: ssh.CmdVars{Command: "uptime"}}, config)
+	m.textInput.SetValue("web")
+	m.refilter()
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlX})
+	m = next.(Model)
+
+	if !m.confirmingAll {
+		t.Fatal("ctrl+x didn't ask to confirm")
+	}
+
+	if got, want := m.confirmAllPrompt(), "Run uptime on 2 hosts? [y/N]"; got != want {
+		t.Errorf("prompt = %q, want %q", got, want)
+	}
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	m = next.(Model)
+
+	names := map[string]bool{}
+	for _, h := range m.runOnAll {
+		names[h.Name] = true
+	}
+
+	if len(m.runOnAll) != 2 || !names["web1"] || !names["web2"] {
+		t.Errorf("running on %d hosts %v, want exactly the filtered web1 and web2", len(m.runOnAll), names)
+	}
+}
+
+func TestRunOnAllShownOnly(t *testing.T) {
+	var config strings.Builder
+	for i := range 5 {
+		fmt.Fprintf(&config, "Host web%d\n  HostName 10.0.0.%d\n", i, i)
+	}
+
+	m := configModel(t, Options{Vars: ssh.CmdVars{Command: "uptime"}, MaxHosts: 3, GroupByPrefix: true}, config.String())
+	m.textInput.SetValue("web")
+	m.refilter()
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlX})
+	m = next.(Model)
+
+	if got, want := m.confirmAllPrompt(), "Run uptime on the 3 hosts shown of 5 matched? [y/N]"; got != want {
+		t.Errorf("prompt = %q, want %q", got, want)
+	}
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	m = next.(Model)
+
+	// Exactly the hosts in the table, without the group header
+	var shown []*ssh.Host
+	for _, h := range m.rowHosts {
+		if h != nil {
+			shown = append(shown, h)
+		}
+	}
+
+	if !slices.Equal(m.runOnAll, shown) || len(shown) != 3 {
+		t.Errorf("running on %d hosts, want the %d shown", len(m.runOnAll), len(shown))
+	}
+}
+
+func TestRunOnAllCancelled(t *testing.T) {
+	m := configModel(t, Options{Vars: ssh.CmdVars{Command: "uptime"}}, "Host web1\n")
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlX})
+	next, _ = next.(Model).Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	m = next.(Model)
+
+	if m.confirmingAll || m.runOnAll != nil || m.quitting {
+		t.Error("answering n didn't cancel")
+	}
+}
+
+func TestRunOnAllNeedsCommand(t *testing.T) {
+	m := configModel(t, Options{}, "Host web1\n")
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlX})
+	m = next.(Model)
+
+	if m.confirmingAll {
+		t.Error("asked to confirm without --command")
+	}
+}
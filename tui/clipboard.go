@@ -0,0 +1,31 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// scpTmpl is the skeleton scp command copied with S, for the local file to be
+// filled in after pasting.
+const scpTmpl = "scp ./FILE {{.Name}}:"
+
+// copyCmd copies the command tmpl renders to for the host under the cursor.
+func (m *Model) copyCmd(tmpl string) tea.Cmd {
+	host := m.cursorHost()
+	if host == nil {
+		return nil
+	}
+
+	cmd, err := host.RenderCmd(tmpl, m.vars())
+	if err != nil {
+		return m.setStatus(fmt.Sprintf("could not render command: %v", err), defaultStatusTTL)
+	}
+
+	if err := clipboard.WriteAll(cmd); err != nil {
+		return m.setStatus(fmt.Sprintf("could not copy to clipboard: %v", err), defaultStatusTTL)
+	}
+
+	return m.setStatus("copied: "+cmd, defaultStatusTTL)
+}
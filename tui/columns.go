@@ -0,0 +1,105 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// hideColumns zeroes the width of the hidden columns, which the table then
+// skips along with their cells, and gives the space they free to the widest
+// column left. Rows keep a cell for every column either way.
+func (m *Model) hideColumns(cols []table.Column) []table.Column {
+	freed, widest := 0, -1
+
+	for i, c := range cols {
+		if m.hiddenCols[c.Title] {
+			freed += c.Width
+			cols[i].Width = 0
+
+			continue
+		}
+
+		if widest < 0 || c.Width > cols[widest].Width {
+			widest = i
+		}
+	}
+
+	if widest >= 0 {
+		cols[widest].Width += freed
+	}
+
+	return cols
+}
+
+// toggleColumn shows or hides the nth column, counting from 1. The last
+// visible column can't be hidden.
+func (m *Model) toggleColumn(n int) tea.Cmd {
+	cols := m.table.Columns()
+	if n < 1 || n > len(cols) {
+		return nil
+	}
+
+	title := cols[n-1].Title
+
+	if !m.hiddenCols[title] && m.visibleColumnCount() == 1 {
+		return m.setStatus("can't hide the last column", defaultStatusTTL)
+	}
+
+	if m.hiddenCols == nil {
+		m.hiddenCols = make(map[string]bool)
+	}
+
+	m.hiddenCols[title] = !m.hiddenCols[title]
+	m.setTableSize(m.width)
+
+	return nil
+}
+
+// visibleColumnCount is how many columns aren't hidden.
+func (m *Model) visibleColumnCount() int {
+	n := 0
+	for _, c := range m.table.Columns() {
+		if !m.hiddenCols[c.Title] {
+			n++
+		}
+	}
+
+	return n
+}
+
+// updateColumns handles keys in columns mode: a number toggles that column
+// and anything else leaves the mode.
+func (m Model) updateColumns(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+	if len(key) == 1 && key[0] >= '1' && key[0] <= '9' {
+		return m, m.toggleColumn(int(key[0] - '0'))
+	}
+
+	m.columnsMode = false
+
+	return m, nil
+}
+
+// columnsPrompt lists the columns by number, marking the hidden ones.
+func (m Model) columnsPrompt() string {
+	cols := m.table.Columns()
+
+	items := make([]string, 0, len(cols))
+	for i, c := range cols {
+		if i == 9 {
+			break
+		}
+
+		mark := "✓"
+		if m.hiddenCols[c.Title] {
+			mark = "✗"
+		}
+
+		items = append(items, fmt.Sprintf("%d %s %s", i+1, c.Title, mark))
+	}
+
+	return "columns: " + strings.Join(items, "  ") + " (any other key to finish)"
+}
@@ -0,0 +1,89 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestColumnsMode(t *testing.T) {
+	m := configModel(t, Options{}, "Host web1\n  User root\n")
+
+	totalWidth := func() int {
+		total := 0
+		for _, c := range m.table.Columns() {
+			total += c.Width
+		}
+
+		return total
+	}
+
+	press := func(msg tea.Msg) {
+		t.Helper()
+
+		next, _ := m.Update(msg)
+		m = next.(Model)
+	}
+
+	press(tea.WindowSizeMsg{Width: 120, Height: 40})
+
+	width := totalWidth()
+	cells := len(m.table.Rows()[0])
+
+	press(tea.KeyMsg{Type: tea.KeyCtrlK})
+
+	if !m.columnsMode {
+		t.Fatal("ctrl+k didn't enter columns mode")
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("3")})
+
+	if user := m.table.Columns()[2]; user.Title != "User" || user.Width != 0 {
+		t.Errorf("column 3 = %s with width %d, want User hidden", user.Title, user.Width)
+	}
+
+	if got := len(m.table.Rows()[0]); got != cells {
+		t.Errorf("rows have %d cells, want %d", got, cells)
+	}
+
+	if got := totalWidth(); got != width {
+		t.Errorf("columns take %d, want the %d freed given to another", got, width)
+	}
+
+	if !strings.Contains(m.columnsPrompt(), "3 User ✗") {
+		t.Errorf("prompt %q doesn't mark User hidden", m.columnsPrompt())
+	}
+
+	// Toggling again shows it
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("3")})
+
+	if m.hiddenCols["User"] {
+		t.Error("pressing 3 again didn't show User")
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if m.columnsMode {
+		t.Error("another key didn't leave columns mode")
+	}
+}
+
+func TestColumnsModeLastColumn(t *testing.T) {
+	m := configModel(t, Options{}, "Host web1\n")
+
+	n := len(m.table.Columns())
+	for i := 1; i < n; i++ {
+		m.toggleColumn(i)
+	}
+
+	m.toggleColumn(n)
+
+	if got := m.visibleColumnCount(); got != 1 {
+		t.Errorf("%d columns visible, want the last kept", got)
+	}
+
+	if !strings.Contains(m.status, "last column") {
+		t.Errorf("status = %q, want a note that the last column stays", m.status)
+	}
+}
@@ -0,0 +1,88 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pix-xip/pssh/ssh"
+)
+
+// diagnosticsPane lists the problems found while loading the config, such as
+// unknown options or missing includes, scrolled to offset.
+type diagnosticsPane struct {
+	diags  []ssh.Diagnostic
+	offset int
+}
+
+// showDiagnostics opens the diagnostics pane for the last load of hosts.
+func (m *Model) showDiagnostics() {
+	m.diagnostics = &diagnosticsPane{diags: m.diagnosticList()}
+}
+
+// diagnosticList returns the problems found by the last load of hosts.
+func (m Model) diagnosticList() []ssh.Diagnostic {
+	if m.opts.Diagnostics == nil {
+		return nil
+	}
+
+	return m.opts.Diagnostics()
+}
+
+// diagnosticsHeight is how many diagnostics fit in the pane, leaving room for
+// the search, footer and the pane's own header and hints.
+func (m Model) diagnosticsHeight() int {
+	return max(m.height-10, 3)
+}
+
+// updateDiagnostics handles keys while the diagnostics pane is open: up and
+// down scroll and esc or W closes it.
+func (m Model) updateDiagnostics(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.diagnostics
+	last := max(len(p.diags)-m.diagnosticsHeight(), 0)
+
+	switch msg.String() {
+	case "esc", "ctrl+c", "W", "q":
+		m.diagnostics = nil
+	case "up", "k":
+		p.offset = max(p.offset-1, 0)
+	case "down", "j":
+		p.offset = min(p.offset+1, last)
+	case "pgup":
+		p.offset = max(p.offset-m.diagnosticsHeight(), 0)
+	case "pgdown":
+		p.offset = min(p.offset+m.diagnosticsHeight(), last)
+	case "home", "g":
+		p.offset = 0
+	case "end", "G":
+		p.offset = last
+	}
+
+	return m, nil
+}
+
+// View lists the diagnostics which fit in height lines from the offset.
+func (p *diagnosticsPane) View(height int) string {
+	return pickerStyle.Render(strings.Join(p.lines(height), "\n"))
+}
+
+// lines are the lines of the pane, showing at most height diagnostics.
+func (p *diagnosticsPane) lines(height int) []string {
+	if len(p.diags) == 0 {
+		return []string{"No problems found loading the config", "", "esc to close"}
+	}
+
+	lines := []string{fmt.Sprintf("Problems found loading the config (%d):", len(p.diags)), ""}
+
+	end := min(p.offset+height, len(p.diags))
+	for _, d := range p.diags[p.offset:end] {
+		lines = append(lines, d.String())
+	}
+
+	hint := "esc to close"
+	if len(p.diags) > height {
+		hint = fmt.Sprintf("%d-%d of %d • up/down to scroll • %s", p.offset+1, end, len(p.diags), hint)
+	}
+
+	return append(lines, "", hint)
+}
@@ -0,0 +1,76 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pix-xip/pssh/ssh"
+)
+
+func TestDiagnosticsPane(t *testing.T) {
+	var diags []ssh.Diagnostic
+	for i := range 20 {
+		diags = append(diags, ssh.Diagnostic{File: "config", Line: i + 1, Message: fmt.Sprint("problem ", i+1)})
+	}
+
+	m := configModel(t, Options{Diagnostics: func() []ssh.Diagnostic { return diags }}, "Host web1\n")
+
+	press := func(msg tea.Msg) {
+		t.Helper()
+
+		next, _ := m.Update(msg)
+		m = next.(Model)
+	}
+
+	press(tea.WindowSizeMsg{Width: 120, Height: 15})
+
+	if !strings.Contains(m.footer(), "W for 20 config warnings") {
+		t.Errorf("footer %q doesn't hint at W", m.footer())
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("W")})
+
+	if m.diagnostics == nil {
+		t.Fatal("W didn't open the diagnostics pane")
+	}
+
+	// 15 rows leave room for 5 diagnostics
+	lines := m.diagnostics.lines(m.diagnosticsHeight())
+	if lines[2] != "config:1: problem 1" || lines[len(lines)-1] != "1-5 of 20 • up/down to scroll • esc to close" {
+		t.Errorf("pane lines = %q", lines)
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyDown})
+	press(tea.KeyMsg{Type: tea.KeyEnd})
+	press(tea.KeyMsg{Type: tea.KeyDown})
+
+	if m.diagnostics.offset != 15 {
+		t.Errorf("offset at the end = %d, want 15", m.diagnostics.offset)
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyEsc})
+
+	if m.diagnostics != nil || m.quitting {
+		t.Error("esc didn't just close the pane")
+	}
+}
+
+func TestDiagnosticsPaneSearching(t *testing.T) {
+	m := configModel(t, Options{}, "Host web1\n")
+	m.textInput.SetValue("we")
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("W")})
+	m = next.(Model)
+
+	if m.diagnostics != nil {
+		t.Error("W opened the pane while searching")
+	}
+
+	m.showDiagnostics()
+
+	if got := m.diagnostics.lines(5)[0]; got != "No problems found loading the config" {
+		t.Errorf("empty pane starts %q", got)
+	}
+}
@@ -0,0 +1,90 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pix-xip/pssh/ssh"
+)
+
+// lineEditors are editors known to open a file at a line given as +LINE
+// before it.
+var lineEditors = map[string]bool{
+	"vi": true, "vim": true, "nvim": true, "nano": true, "emacs": true,
+	"emacsclient": true, "micro": true, "kak": true, "joe": true, "mg": true,
+}
+
+// editorCmd builds the command to edit path with $EDITOR, falling back to vi.
+// $EDITOR may include arguments, e.g. "code --wait".
+func editorCmd(path string) *exec.Cmd {
+	return editorAtCmd(path, 0)
+}
+
+// editorAtCmd is editorCmd opening path at line, if the editor is known to
+// support it and line isn't zero.
+func editorAtCmd(path string, line int) *exec.Cmd {
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		editor = []string{"vi"}
+	}
+
+	args := editor[1:]
+	if line > 0 && lineEditors[filepath.Base(editor[0])] {
+		args = append(args, "+"+strconv.Itoa(line))
+	}
+
+	args = append(args, ssh.ExpandHome(path))
+
+	return exec.Command(editor[0], args...)
+}
+
+// editConfig suspends the TUI to edit the primary ssh config file.
+func (m *Model) editConfig() tea.Cmd {
+	if strings.HasPrefix(m.opts.SSHConfig, "https://") {
+		return m.setStatus("can't edit a remote ssh config", defaultStatusTTL)
+	}
+
+	return runExternal(editorCmd(m.opts.SSHConfig))
+}
+
+// editHost suspends the TUI to edit the config file defining the host under
+// the cursor, opened at its block.
+func (m *Model) editHost() tea.Cmd {
+	host := m.cursorHost()
+	if host == nil {
+		return nil
+	}
+
+	if host.SourceFile == "" {
+		return m.setStatus("don't know where "+host.Name+" is defined", defaultStatusTTL)
+	}
+
+	if strings.HasPrefix(host.SourceFile, "https://") {
+		return m.setStatus("can't edit a remote ssh config", defaultStatusTTL)
+	}
+
+	return runExternal(editorAtCmd(host.SourceFile, host.SourceLine))
+}
+
+// reload reloads the hosts, e.g. after the config has been edited, keeping
+// the current search and --changed filter.
+func (m *Model) reload() tea.Cmd {
+	hosts, err := m.opts.LoadHosts()
+	if err != nil {
+		return m.setStatus(fmt.Sprintf("could not reload hosts: %v", err), defaultStatusTTL)
+	}
+
+	hosts = selectableHosts(hosts)
+	m.hashes = hostHashes(hosts)
+	m.hosts = m.changedHosts(hosts)
+	m.resolveAuth()
+	m.setTableSize(m.width)
+	m.refilter()
+
+	return m.setStatus(fmt.Sprintf("reloaded %d hosts", len(m.hosts)), defaultStatusTTL)
+}
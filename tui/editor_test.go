@@ -0,0 +1,104 @@
+package tui
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pix-xip/pssh/ssh"
+)
+
+func TestEditorCmd(t *testing.T) {
+	t.Setenv("HOME", "/home/me")
+
+	tests := []struct {
+		editor string
+		want   []string
+	}{
+		{"", []string{"vi", "/home/me/.ssh/config"}},
+		{"nano", []string{"nano", "/home/me/.ssh/config"}},
+		{"code --wait", []string{"code", "--wait", "/home/me/.ssh/config"}},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("EDITOR", tt.editor)
+
+		if got := editorCmd("~/.ssh/config").Args; !slices.Equal(got, tt.want) {
+			t.Errorf("EDITOR=%q: args = %q, want %q", tt.editor, got, tt.want)
+		}
+	}
+}
+
+func TestReload(t *testing.T) {
+	m := configModel(t, Options{}, "Host web1\n")
+
+	if err := os.WriteFile(m.opts.SSHConfig, []byte("Host web1\nHost db1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	m.reload()
+
+	if len(m.hosts) != 2 || len(m.table.Rows()) != 2 {
+		t.Errorf("after reload: %d hosts, %d rows, want 2", len(m.hosts), len(m.table.Rows()))
+	}
+
+	if m.status != "reloaded 2 hosts" {
+		t.Errorf("status = %q", m.status)
+	}
+
+	// A missing config is skipped, but one which can't be read fails
+	if err := os.Remove(m.opts.SSHConfig); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Mkdir(m.opts.SSHConfig, 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	m.reload()
+
+	if len(m.hosts) != 2 || !strings.HasPrefix(m.status, "could not reload hosts") {
+		t.Errorf("after a failed reload: %d hosts, status %q, want the hosts kept", len(m.hosts), m.status)
+	}
+}
+
+func TestEditorAtCmd(t *testing.T) {
+	tests := []struct {
+		editor string
+		line   int
+		want   []string
+	}{
+		{"", 12, []string{"vi", "+12", "/etc/ssh/config"}},
+		{"/usr/bin/nvim", 3, []string{"/usr/bin/nvim", "+3", "/etc/ssh/config"}},
+		{"vim", 0, []string{"vim", "/etc/ssh/config"}},
+		// Editors not known to take +LINE just open the file
+		{"code --wait", 12, []string{"code", "--wait", "/etc/ssh/config"}},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("EDITOR", tt.editor)
+
+		if got := editorAtCmd("/etc/ssh/config", tt.line).Args; !slices.Equal(got, tt.want) {
+			t.Errorf("EDITOR=%q line %d: args = %q, want %q", tt.editor, tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestEditHostRefused(t *testing.T) {
+	tests := []struct {
+		host *ssh.Host
+		want string
+	}{
+		{&ssh.Host{Name: "web1"}, "don't know where web1 is defined"},
+		{&ssh.Host{Name: "web1", SourceFile: "https://example.com/config"}, "can't edit a remote ssh config"},
+	}
+
+	for _, tt := range tests {
+		m := testModel(t, Options{}, func() []*ssh.Host { return []*ssh.Host{tt.host} })
+
+		if cmd := m.editHost(); cmd == nil || m.status != tt.want {
+			t.Errorf("editing %+v: status = %q, want %q", tt.host, m.status, tt.want)
+		}
+	}
+}
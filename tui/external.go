@@ -0,0 +1,39 @@
+package tui
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// externalFinishedMsg is sent when a command started with runExternal exits.
+type externalFinishedMsg struct {
+	name string
+	err  error
+}
+
+// runExternal suspends the TUI to run cmd in the terminal, resuming once it
+// exits. All actions which hand the terminal to another program go through
+// here so they restore the screen the same way.
+func runExternal(cmd *exec.Cmd) tea.Cmd {
+	name := filepath.Base(cmd.Path)
+
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return externalFinishedMsg{name: name, err: err}
+	})
+}
+
+// externalFinished re-lays out the TUI after an external command, reloading
+// the hosts in case it changed the config.
+func (m *Model) externalFinished(msg externalFinishedMsg) tea.Cmd {
+	if msg.err != nil {
+		return tea.Batch(
+			tea.WindowSize(),
+			m.setStatus(fmt.Sprintf("%s failed: %v", msg.name, msg.err), defaultStatusTTL),
+		)
+	}
+
+	return tea.Batch(tea.WindowSize(), m.reload())
+}
@@ -0,0 +1,29 @@
+package tui
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestExternalFinished(t *testing.T) {
+	m := configModel(t, Options{}, "Host web1\n")
+
+	if err := os.WriteFile(m.opts.SSHConfig, []byte("Host web1\nHost db1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	next, _ := m.Update(externalFinishedMsg{name: "vim", err: errors.New("exit status 1")})
+	m = next.(Model)
+
+	if m.status != "vim failed: exit status 1" || len(m.hosts) != 1 {
+		t.Errorf("after a failure: status %q, %d hosts, want an error and no reload", m.status, len(m.hosts))
+	}
+
+	next, _ = m.Update(externalFinishedMsg{name: "vim"})
+	m = next.(Model)
+
+	if len(m.hosts) != 2 {
+		t.Errorf("got %d hosts, want the config reloaded", len(m.hosts))
+	}
+}
@@ -0,0 +1,150 @@
+package tui
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/pix-xip/pssh/ssh"
+)
+
+// searchField restricts the fuzzy search to one column.
+type searchField int
+
+const (
+	fieldAll searchField = iota
+	fieldUser
+	fieldHostname
+	// fieldOptions searches all fields along with every option and comment in
+	// the host's config block.
+	fieldOptions
+)
+
+// fieldKeys are the keys scoping the search to a field. Pressing the key of
+// the active field again searches all fields.
+var fieldKeys = map[string]searchField{
+	"ctrl+u": fieldUser,
+	"alt+h":  fieldHostname,
+	"/":      fieldOptions,
+}
+
+func (f searchField) String() string {
+	switch f {
+	case fieldUser:
+		return "user"
+	case fieldHostname:
+		return "hostname"
+	case fieldOptions:
+		return "all fields and options"
+	default:
+		return "all fields"
+	}
+}
+
+// toggle switches to field, or back to all fields if it's already active.
+func (f searchField) toggle(field searchField) searchField {
+	if f == field {
+		return fieldAll
+	}
+
+	return field
+}
+
+// maxSearchAliases caps how many aliases of a host are searched, so a host
+// with a huge alias list neither slows the search nor outranks better matches.
+const maxSearchAliases = 32
+
+// searchAliases returns the aliases of host to search: without duplicates or
+// repeats of the name, ignoring case, and at most maxSearchAliases of them.
+func searchAliases(host *ssh.Host) []string {
+	aliases := make([]string, 0, min(len(host.Aliases), maxSearchAliases))
+
+	for _, a := range host.Aliases {
+		if len(aliases) == maxSearchAliases {
+			break
+		}
+
+		if strings.EqualFold(a, host.Name) || slices.ContainsFunc(aliases, func(s string) bool {
+			return strings.EqualFold(s, a)
+		}) {
+			continue
+		}
+
+		aliases = append(aliases, a)
+	}
+
+	return aliases
+}
+
+// column is a table column a part of the search target is shown in.
+type column int
+
+const (
+	colName column = iota
+	colAliases
+	colUser
+	colHostname
+	colPort
+	colProfile
+	colDescription
+	colIdentity
+	// colOptions isn't shown, so matches in it aren't highlighted.
+	colOptions
+)
+
+// targetPart is the text of one column matched against the search.
+type targetPart struct {
+	col  column
+	text string
+}
+
+// parts are the texts of host matched against the search for this field.
+func (f searchField) parts(host *ssh.Host) []targetPart {
+	switch f {
+	case fieldUser:
+		return []targetPart{{colUser, host.User}}
+	case fieldHostname:
+		return []targetPart{{colHostname, host.Hostname}}
+	case fieldOptions:
+		return append(fieldAll.parts(host), targetPart{colOptions, blockText(host)})
+	default:
+		return []targetPart{
+			{colName, host.Name},
+			{colAliases, strings.Join(searchAliases(host), " ")},
+			{colUser, host.User},
+			{colHostname, host.Hostname},
+			{colPort, host.Port},
+			{colProfile, host.Profile},
+			{colDescription, host.Description},
+			{colIdentity, identities(host)},
+		}
+	}
+}
+
+// target is the text of host matched against the search for this field: its
+// parts joined by sep.
+func (f searchField) target(host *ssh.Host, sep string) string {
+	parts := f.parts(host)
+
+	texts := make([]string, len(parts))
+	for i, p := range parts {
+		texts[i] = p.text
+	}
+
+	return strings.Join(texts, sep)
+}
+
+// blockText is the options and comments of host's config block as searched,
+// e.g. "ProxyJump bastion role: web".
+func blockText(host *ssh.Host) string {
+	var texts []string
+	for _, o := range host.Options() {
+		texts = append(texts, o.Key+" "+o.Value)
+	}
+
+	return strings.Join(append(texts, host.Comments()...), " ")
+}
+
+// identities are the identity files of host as searched and shown.
+func identities(host *ssh.Host) string {
+	return strings.Join(host.IdentityFiles, " ")
+}
@@ -0,0 +1,129 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pix-xip/pssh/ssh"
+)
+
+func TestSearchField(t *testing.T) {
+	m := testModel(t, Options{}, func() []*ssh.Host {
+		return []*ssh.Host{
+			{Name: "web1", User: "alice", Hostname: "bob.example.com"},
+			{Name: "db1", User: "bob", Hostname: "10.0.0.2"},
+		}
+	})
+
+	m.textInput.SetValue("bob")
+
+	for _, tt := range []struct {
+		field searchField
+		want  []string
+	}{
+		{fieldAll, []string{"web1", "db1"}},
+		{fieldUser, []string{"db1"}},
+		{fieldHostname, []string{"web1"}},
+	} {
+		m.field = tt.field
+		m.refilter()
+
+		var got []string
+		for _, h := range m.filteredHosts {
+			got = append(got, h.Name)
+		}
+
+		if !slices.Equal(slices.Sorted(slices.Values(got)), slices.Sorted(slices.Values(tt.want))) {
+			t.Errorf("searching %s matched %q, want %q", tt.field, got, tt.want)
+		}
+	}
+
+	// The field's key toggles it on and back off
+	m.field = fieldAll
+	for _, want := range []searchField{fieldUser, fieldAll} {
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlU})
+		if m = next.(Model); m.field != want {
+			t.Errorf("after ctrl+u: field = %s, want %s", m.field, want)
+		}
+	}
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("h"), Alt: true})
+	if m = next.(Model); m.field != fieldHostname {
+		t.Errorf("after alt+h: field = %s, want %s", m.field, fieldHostname)
+	}
+
+	// Terminals sending ctrl+h for backspace still delete from the search
+	m.field = fieldAll
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlH})
+	if m = next.(Model); m.field != fieldAll || m.textInput.Value() != "bo" {
+		t.Errorf("after ctrl+h: field = %s, search = %q, want %s and %q", m.field, m.textInput.Value(), fieldAll, "bo")
+	}
+}
+
+func TestSearchAliases(t *testing.T) {
+	host := &ssh.Host{Name: "web1", Aliases: []string{"www", "WEB1", "Www", "frontend"}}
+	if got, want := searchAliases(host), []string{"www", "frontend"}; !slices.Equal(got, want) {
+		t.Errorf("searchAliases = %q, want %q", got, want)
+	}
+
+	var many []string
+	for i := range maxSearchAliases + 10 {
+		many = append(many, fmt.Sprint("alias", i))
+	}
+
+	if got := searchAliases(&ssh.Host{Name: "web1", Aliases: many}); len(got) != maxSearchAliases {
+		t.Errorf("searched %d aliases, want the cap of %d", len(got), maxSearchAliases)
+	}
+}
+
+func TestAliasIndexes(t *testing.T) {
+	// "web db" is searched, "(web, WEB, db)" is shown
+	searched, shown := []string{"web", "db"}, []string{"web", "WEB", "db"}
+
+	got := aliasIndexes(searched, shown, aliasLead(ssh.AliasParen), []int{0, 2, 3, 4, 5})
+	if want := []int{1, 3, 11, 12}; !slices.Equal(got, want) {
+		t.Errorf("aliasIndexes = %v, want %v", got, want)
+	}
+
+	// Comma separated aliases start at the beginning of the column
+	got = aliasIndexes(searched, shown, aliasLead(ssh.AliasComma), []int{0, 4})
+	if want := []int{0, 10}; !slices.Equal(got, want) {
+		t.Errorf("aliasIndexes comma = %v, want %v", got, want)
+	}
+}
+
+func TestSearchOptions(t *testing.T) {
+	m := configModel(t, Options{}, "Host web1\n  HostName 10.0.0.1\n  ProxyJump bastion\n\nHost db1 # tier: backend\n  HostName 10.0.0.2\n")
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	if m = next.(Model); m.field != fieldOptions {
+		t.Fatalf("after /: field = %s, want %s", m.field, fieldOptions)
+	}
+
+	for query, want := range map[string][]string{
+		"bastion": {"web1"},
+		"backend": {"db1"},
+		"10.0.0":  {"db1", "web1"},
+	} {
+		m.textInput.SetValue(query)
+		m.refilter()
+
+		var got []string
+		for _, h := range m.filteredHosts {
+			got = append(got, h.Name)
+		}
+
+		if slices.Sort(got); !slices.Equal(got, want) {
+			t.Errorf("searching options for %q matched %q, want %q", query, got, want)
+		}
+	}
+
+	// With a search typed, / is searched for rather than toggling the scope
+	m.textInput.SetValue("a")
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	if m = next.(Model); m.field != fieldOptions {
+		t.Errorf("/ in a search changed the field to %s", m.field)
+	}
+}
@@ -0,0 +1,98 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pix-xip/pssh/ssh"
+)
+
+var groupHeaderStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("212"))
+
+// hostGroup is a run of hosts sharing a name prefix.
+type hostGroup struct {
+	prefix string
+	hosts  []*ssh.Host
+}
+
+// hostPrefix returns the part of name before the first '-', or "" if it has
+// no prefix.
+func hostPrefix(name string) string {
+	prefix, _, found := strings.Cut(name, "-")
+	if !found {
+		return ""
+	}
+
+	return prefix
+}
+
+// groupByPrefix collects hosts into groups by name prefix. Groups are ordered
+// by their first host, and hosts keep their relative order, so search ranking
+// is preserved within and across groups.
+func groupByPrefix(hosts []*ssh.Host) []hostGroup {
+	var groups []hostGroup
+
+	index := make(map[string]int)
+
+	for _, h := range hosts {
+		prefix := hostPrefix(h.Name)
+
+		i, ok := index[prefix]
+		if !ok {
+			i = len(groups)
+			index[prefix] = i
+			groups = append(groups, hostGroup{prefix: prefix})
+		}
+
+		groups[i].hosts = append(groups[i].hosts, h)
+	}
+
+	return groups
+}
+
+// filterToGroup limits the hosts to those sharing the name prefix of the host
+// under the cursor.
+func (m *Model) filterToGroup() tea.Cmd {
+	host := m.cursorHost()
+	if host == nil {
+		return nil
+	}
+
+	prefix := hostPrefix(host.Name)
+	if prefix == "" {
+		return m.setStatus(host.Name+" isn't in a group", defaultStatusTTL)
+	}
+
+	m.groupFilter = prefix
+	m.refilter()
+	m.table.GotoTop()
+
+	return nil
+}
+
+// groupHeaderRow is the table row shown above a group of hosts.
+func (m *Model) groupHeaderRow(prefix string) table.Row {
+	label := prefix
+	if label == "" {
+		label = "other"
+	}
+
+	row := make(table.Row, len(m.table.Columns()))
+	row[0] = styleCell(groupHeaderStyle, "▾ "+label, m.table.Columns()[0].Width)
+
+	return row
+}
+
+// styleCell renders s with style if it will fit in a column of width. The
+// table truncates cells without regard for escape codes, so anything longer
+// is left unstyled rather than having its styling cut off.
+func styleCell(style lipgloss.Style, s string, width int) string {
+	styled := style.Render(s)
+	if len(styled)-len(s)+lipgloss.Width(s) > width {
+		return s
+	}
+
+	return styled
+}
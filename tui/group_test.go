@@ -0,0 +1,125 @@
+package tui
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pix-xip/pssh/ssh"
+)
+
+func TestGroupByPrefix(t *testing.T) {
+	var hosts []*ssh.Host
+	for _, name := range []string{"prod-web", "bastion", "dev-web", "prod-db", "dev-db"} {
+		hosts = append(hosts, &ssh.Host{Name: name})
+	}
+
+	type group struct {
+		prefix string
+		names  []string
+	}
+
+	var got []group
+	for _, g := range groupByPrefix(hosts) {
+		var names []string
+		for _, h := range g.hosts {
+			names = append(names, h.Name)
+		}
+
+		got = append(got, group{g.prefix, names})
+	}
+
+	want := []group{
+		{"prod", []string{"prod-web", "prod-db"}},
+		{"", []string{"bastion"}},
+		{"dev", []string{"dev-web", "dev-db"}},
+	}
+
+	if !slices.EqualFunc(got, want, func(a, b group) bool {
+		return a.prefix == b.prefix && slices.Equal(a.names, b.names)
+	}) {
+		t.Errorf("groupByPrefix = %+v, want %+v", got, want)
+	}
+}
+
+func TestGroupHeaderRows(t *testing.T) {
+	m := configModel(t, Options{GroupByPrefix: true}, "Host prod-web\n")
+
+	web, db := &ssh.Host{Name: "prod-web"}, &ssh.Host{Name: "bastion"}
+	m.setRows([]*ssh.Host{web, db})
+
+	if want := []*ssh.Host{nil, web, nil, db}; !slices.Equal(m.rowHosts, want) {
+		t.Fatalf("rowHosts = %v, want headers before each group", m.rowHosts)
+	}
+
+	if rows := m.table.Rows(); len(rows) != 4 || !strings.Contains(rows[2][0], "other") {
+		t.Errorf("rows = %q, want an %q header for hosts without a prefix", rows, "other")
+	}
+
+	// enter on a header row selects nothing
+	m.table.SetCursor(0)
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m = next.(Model); m.quitting || m.selectedHost != nil {
+		t.Error("enter on a group header selected a host")
+	}
+
+	m.table.SetCursor(1)
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m = next.(Model); m.selectedHost != web {
+		t.Errorf("enter selected %v, want %s", m.selectedHost, web.Name)
+	}
+}
+
+func TestFilterToGroup(t *testing.T) {
+	const config = "Host prod-web\n  HostName 10.0.0.1\nHost prod-db\n  HostName 10.0.0.2\nHost dev-web\n  HostName 10.0.0.3\nHost bastion\n  HostName 10.0.0.4\n"
+
+	m := configModel(t, Options{}, config)
+
+	// Put the cursor on prod-db
+	m.table.SetCursor(slices.IndexFunc(m.rowHosts, func(h *ssh.Host) bool { return h.Name == "prod-db" }))
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(".")})
+	m = next.(Model)
+
+	var names []string
+	for _, h := range m.filteredHosts {
+		names = append(names, h.Name)
+	}
+
+	slices.Sort(names)
+
+	if want := []string{"prod-db", "prod-web"}; m.groupFilter != "prod" || !slices.Equal(names, want) {
+		t.Fatalf("group %q shows %q, want prod showing %q", m.groupFilter, names, want)
+	}
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = next.(Model)
+
+	if m.groupFilter != "" || len(m.filteredHosts) != 4 || m.quitting {
+		t.Errorf("esc left group %q with %d hosts, want the filter cleared", m.groupFilter, len(m.filteredHosts))
+	}
+
+	// Hosts without a prefix aren't in a group
+	m.table.SetCursor(slices.IndexFunc(m.rowHosts, func(h *ssh.Host) bool { return h.Name == "bastion" }))
+	m.filterToGroup()
+
+	if m.groupFilter != "" {
+		t.Errorf("bastion filtered to group %q, want none", m.groupFilter)
+	}
+}
+
+func TestFilterToGroupSearching(t *testing.T) {
+	m := configModel(t, Options{}, "Host prod-web\n")
+	m.textInput.Focus()
+	m.textInput.SetValue("prod")
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(".")})
+	m = next.(Model)
+
+	if m.groupFilter != "" {
+		t.Errorf(". while searching filtered to group %q", m.groupFilter)
+	}
+}
@@ -0,0 +1,91 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// keyHelp describes what a key does, for the help pane.
+type keyHelp struct {
+	key, desc string
+}
+
+// keyHelps lists the keys available while the table is focused. Some depend
+// on the options, e.g. editing is left out when read-only.
+func (m Model) keyHelps() []keyHelp {
+	keys := []keyHelp{
+		{"enter", "connect, or print the command (ctrl+t toggles)"},
+		{"esc", "clear the search or group, then quit"},
+		{"up/down, alt+j/k", "move a row"},
+		{"alt+g/G", "jump to the first or last row"},
+		{"alt+d/u", "move half a page"},
+		{"tab", "switch to the search history"},
+		{"ctrl+u, alt+h", "search only users or hostnames"},
+		{"/", "search options and comments (empty search)"},
+		{"ctrl+r", "cycle the sort column"},
+		{".", "only show the host's group (empty search)"},
+		{"ctrl+g", "pick an identity file"},
+		{"alt+a", "pick an alias to connect by"},
+		{"ctrl+n", "log in as another user"},
+		{"ctrl+y", "copy the connect command"},
+		{"S", "copy an scp command (empty search)"},
+		{"ctrl+s", "show the host's options"},
+		{"ctrl+p", "toggle the config preview"},
+		{"ctrl+k", "show or hide columns"},
+		{"ctrl+l", "reload the theme"},
+	}
+
+	if m.opts.Vars.Command != "" {
+		keys = append(keys, keyHelp{"ctrl+x", "run the command on every host shown"})
+	}
+
+	if !m.opts.ReadOnly {
+		keys = append(keys,
+			keyHelp{"ctrl+o", "edit the ssh config"},
+			keyHelp{"alt+e", "edit the host's block"},
+		)
+	}
+
+	if n := len(m.diagnosticList()); n > 0 {
+		keys = append(keys, keyHelp{"W", fmt.Sprintf("show %d config warnings (empty search)", n)})
+	}
+
+	return keys
+}
+
+// updateHelp handles keys while the help pane is open: esc, ? or q close it.
+func (m Model) updateHelp(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "ctrl+c", "?", "q":
+		m.help = false
+	}
+
+	return m, nil
+}
+
+// helpView lists the keys in two columns, to fit the height of the table.
+func (m Model) helpView() string {
+	keys := m.keyHelps()
+	half := (len(keys) + 1) / 2
+
+	column := func(keys []keyHelp) string {
+		width := 0
+		for _, k := range keys {
+			width = max(width, len(k.key))
+		}
+
+		lines := make([]string, len(keys))
+		for i, k := range keys {
+			lines[i] = fmt.Sprintf("%-*s  %s", width, k.key, k.desc)
+		}
+
+		return strings.Join(lines, "\n")
+	}
+
+	cols := lipgloss.JoinHorizontal(lipgloss.Top, column(keys[:half]), "    ", column(keys[half:]))
+
+	return pickerStyle.Render("Keys\n\n" + cols + "\n\nesc to close")
+}
@@ -0,0 +1,125 @@
+package tui
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pix-xip/pssh/ssh"
+)
+
+// Bold and underline are switched on and off individually rather than with a
+// lipgloss style, whose reset would also clear the selected row's colours.
+const (
+	highlightOn  = "\x1b[1;4m"
+	highlightOff = "\x1b[22;24m"
+)
+
+// splitMatches maps the indexes fuzzy matched in a target, the parts joined by
+// sep, back to indexes within each part.
+func splitMatches(parts []targetPart, sep string, matched []int) map[column][]int {
+	byCol := make(map[column][]int)
+
+	start, i := 0, 0
+	for _, p := range parts {
+		end := start + len(p.text)
+
+		for ; i < len(matched) && matched[i] < end+len(sep); i++ {
+			if matched[i] < end {
+				byCol[p.col] = append(byCol[p.col], matched[i]-start)
+			}
+		}
+
+		start = end + len(sep)
+	}
+
+	return byCol
+}
+
+// aliasLead is how far into the aliases as shown the first alias starts.
+func aliasLead(format ssh.AliasFormat) int {
+	if format == ssh.AliasComma {
+		return 0
+	}
+
+	return len("(")
+}
+
+// aliasIndexes maps indexes within the searched aliases joined by spaces to
+// indexes within the aliases as shown, e.g. "(a, b)" with the first starting
+// at lead.
+func aliasIndexes(searched, shown []string, lead int, indexes []int) []int {
+	// Where each shown alias starts
+	offsets := make([]int, len(shown))
+	for i, pos := 0, lead; i < len(shown); i++ {
+		offsets[i] = pos
+		pos += len(shown[i]) + len(", ")
+	}
+
+	var mapped []int
+
+	start, k := 0, 0
+	for _, idx := range indexes {
+		for k < len(searched) && idx >= start+len(searched[k]) {
+			start += len(searched[k]) + 1
+			k++
+		}
+
+		if k == len(searched) {
+			break
+		}
+
+		if idx < start {
+			// The space between two aliases
+			continue
+		}
+
+		if i := slices.Index(shown, searched[k]); i >= 0 {
+			mapped = append(mapped, offsets[i]+idx-start)
+		}
+	}
+
+	return mapped
+}
+
+// highlight marks the bytes of s at indexes, which must be sorted. As the
+// table truncates cells without skipping escape codes, s is left plain if the
+// marked up cell wouldn't fit in width.
+func highlight(s string, indexes []int, width int) string {
+	if len(indexes) == 0 {
+		return s
+	}
+
+	var b strings.Builder
+
+	on, next := false, 0
+	for i, r := range s {
+		for next < len(indexes) && indexes[next] < i {
+			next++
+		}
+
+		matched := next < len(indexes) && indexes[next] == i
+		if matched != on {
+			if matched {
+				b.WriteString(highlightOn)
+			} else {
+				b.WriteString(highlightOff)
+			}
+
+			on = matched
+		}
+
+		b.WriteRune(r)
+	}
+
+	if on {
+		b.WriteString(highlightOff)
+	}
+
+	styled := b.String()
+	if len(styled)-len(s)+lipgloss.Width(s) > width {
+		return s
+	}
+
+	return styled
+}
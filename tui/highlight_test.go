@@ -0,0 +1,61 @@
+package tui
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestHighlight(t *testing.T) {
+	const on, off = highlightOn, highlightOff
+
+	tests := []struct {
+		name    string
+		s       string
+		indexes []int
+		width   int
+		want    string
+	}{
+		{"no matches", "web1", nil, 40, "web1"},
+		{"run", "web1", []int{0, 1, 2}, 40, on + "web" + off + "1"},
+		{"gaps", "web1", []int{0, 3}, 40, on + "w" + off + "eb" + on + "1" + off},
+		{"to the end", "web1", []int{2, 3}, 40, "we" + on + "b1" + off},
+		// é is two bytes, so the match after it is at byte 3
+		{"multi-byte", "hé-1", []int{1, 3}, 40, "h" + on + "é-" + off + "1"},
+		{"multi-byte run", "ééx", []int{2, 4}, 40, "é" + on + "éx" + off},
+		// The escape codes count towards the width, as the table counts them
+		{"too wide", "web1", []int{0}, 10, "web1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := highlight(tt.s, tt.indexes, tt.width); got != tt.want {
+				t.Errorf("highlight(%q, %v) = %q, want %q", tt.s, tt.indexes, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitMatches(t *testing.T) {
+	parts := []targetPart{{colName, "web1"}, {colUser, "bob"}, {colHostname, "10.0.0.1"}}
+
+	tests := []struct {
+		name    string
+		matched []int
+		want    map[column][]int
+	}{
+		{"none", nil, map[column][]int{}},
+		{"first part", []int{0, 3}, map[column][]int{colName: {0, 3}}},
+		// "web1 bob 10.0.0.1": the separators at 4 and 8 belong to no part
+		{"across parts", []int{1, 4, 5, 7, 9, 16}, map[column][]int{colName: {1}, colUser: {0, 2}, colHostname: {0, 7}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitMatches(parts, " ", tt.matched)
+			if !maps.EqualFunc(got, tt.want, slices.Equal) {
+				t.Errorf("splitMatches(%v) = %v, want %v", tt.matched, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package tui
+
+import "slices"
+
+// maxQueryHistory is how many recent search queries are remembered.
+const maxQueryHistory = 50
+
+// queryHistory is a bounded list of recent search queries, oldest first, with
+// a cursor for shell-style up/down navigation.
+type queryHistory struct {
+	entries []string
+	// pos is the entry being shown while navigating, len(entries) when not.
+	pos int
+	// draft is the query being typed before navigation started.
+	draft string
+}
+
+func newQueryHistory(entries []string) queryHistory {
+	if len(entries) > maxQueryHistory {
+		entries = entries[len(entries)-maxQueryHistory:]
+	}
+
+	entries = slices.Clone(entries)
+
+	return queryHistory{entries: entries, pos: len(entries)}
+}
+
+// add records q as the newest entry, dropping any older duplicate and the
+// oldest entry once full.
+func (h *queryHistory) add(q string) {
+	if q == "" {
+		return
+	}
+
+	h.entries = slices.DeleteFunc(h.entries, func(e string) bool { return e == q })
+	h.entries = append(h.entries, q)
+
+	if len(h.entries) > maxQueryHistory {
+		h.entries = h.entries[len(h.entries)-maxQueryHistory:]
+	}
+
+	h.reset()
+}
+
+// prev moves to the next older entry. current is stashed as the draft when
+// navigation starts so next can restore it.
+func (h *queryHistory) prev(current string) (string, bool) {
+	if h.pos == 0 || len(h.entries) == 0 {
+		return "", false
+	}
+
+	if h.pos == len(h.entries) {
+		h.draft = current
+	}
+
+	h.pos--
+
+	return h.entries[h.pos], true
+}
+
+// next moves to the next newer entry, returning the draft past the newest.
+func (h *queryHistory) next() (string, bool) {
+	if h.pos >= len(h.entries) {
+		return "", false
+	}
+
+	h.pos++
+	if h.pos == len(h.entries) {
+		return h.draft, true
+	}
+
+	return h.entries[h.pos], true
+}
+
+func (h *queryHistory) reset() {
+	h.pos = len(h.entries)
+	h.draft = ""
+}
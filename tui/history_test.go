@@ -0,0 +1,64 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestQueryHistoryNavigation(t *testing.T) {
+	h := newQueryHistory([]string{"web", "db"})
+
+	steps := []struct {
+		name string
+		move func() (string, bool)
+		want string
+		ok   bool
+	}{
+		{"newest", func() (string, bool) { return h.prev("draft") }, "db", true},
+		{"older", func() (string, bool) { return h.prev("db") }, "web", true},
+		{"past the oldest", func() (string, bool) { return h.prev("web") }, "", false},
+		{"newer", h.next, "db", true},
+		{"back to the draft", h.next, "draft", true},
+		{"past the draft", h.next, "", false},
+	}
+
+	for _, s := range steps {
+		got, ok := s.move()
+		if got != s.want || ok != s.ok {
+			t.Fatalf("%s: got (%q, %v), want (%q, %v)", s.name, got, ok, s.want, s.ok)
+		}
+	}
+}
+
+func TestQueryHistoryAdd(t *testing.T) {
+	h := newQueryHistory([]string{"web", "db", "cache"})
+
+	h.add("")
+	h.add("web")
+
+	if want := []string{"db", "cache", "web"}; !slices.Equal(h.entries, want) {
+		t.Errorf("entries = %q, want %q", h.entries, want)
+	}
+
+	if got, _ := h.prev(""); got != "web" {
+		t.Errorf("prev after add = %q, want the added query", got)
+	}
+}
+
+func TestQueryHistoryCap(t *testing.T) {
+	var entries []string
+	for i := range maxQueryHistory + 5 {
+		entries = append(entries, fmt.Sprint("q", i))
+	}
+
+	h := newQueryHistory(entries)
+	if len(h.entries) != maxQueryHistory || h.entries[0] != "q5" {
+		t.Fatalf("loaded %d entries starting at %q, want the newest %d", len(h.entries), h.entries[0], maxQueryHistory)
+	}
+
+	h.add("new")
+	if len(h.entries) != maxQueryHistory || h.entries[0] != "q6" {
+		t.Errorf("after add: %d entries starting at %q, want the oldest dropped", len(h.entries), h.entries[0])
+	}
+}
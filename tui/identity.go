@@ -0,0 +1,77 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pix-xip/pssh/ssh"
+)
+
+var pickerStyle = lipgloss.NewStyle().
+	Border(lipgloss.RoundedBorder()).
+	BorderForeground(lipgloss.Color("57")).
+	Padding(0, 1)
+
+// identityPicker chooses which of a host's identity files to connect with.
+type identityPicker struct {
+	host   *ssh.Host
+	cursor int
+}
+
+// openIdentityPicker opens the picker for the host under the cursor, if it has
+// more than one identity to choose from.
+func (m *Model) openIdentityPicker() tea.Cmd {
+	host := m.cursorHost()
+	if host == nil {
+		return nil
+	}
+
+	if len(host.IdentityFiles) < 2 {
+		return m.setStatus(host.Name+" has no identities to choose from", defaultStatusTTL)
+	}
+
+	m.picker = &identityPicker{host: host}
+
+	return nil
+}
+
+// updatePicker handles keys while the identity picker is open. Choosing an
+// identity selects the host, connecting with it.
+func (m Model) updatePicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.picker
+
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		m.picker = nil
+	case "up", "k":
+		p.cursor = max(p.cursor-1, 0)
+	case "down", "j":
+		p.cursor = min(p.cursor+1, len(p.host.IdentityFiles)-1)
+	case "enter":
+		m.identity = ssh.Identity(p.host.IdentityFiles[p.cursor])
+		m.picker = nil
+
+		return m.selectHost(p.host)
+	}
+
+	return m, nil
+}
+
+func (p *identityPicker) View() string {
+	lines := []string{fmt.Sprintf("Connect to %s with:", p.host.Name), ""}
+
+	for i, f := range p.host.IdentityFiles {
+		prefix := "  "
+		if i == p.cursor {
+			prefix = "> "
+		}
+
+		lines = append(lines, prefix+f)
+	}
+
+	lines = append(lines, "", "enter to connect • esc to cancel")
+
+	return pickerStyle.Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,53 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pix-xip/pssh/ssh"
+)
+
+func TestIdentityPicker(t *testing.T) {
+	web := &ssh.Host{Name: "web1", IdentityFiles: []string{"/keys/work", "/keys/my key"}}
+	m := testModel(t, Options{Tmpl: "ssh {{.Identity}} {{.Name}}"}, func() []*ssh.Host { return []*ssh.Host{web} })
+
+	send := func(msg tea.KeyMsg) {
+		t.Helper()
+
+		next, _ := m.Update(msg)
+		m = next.(Model)
+	}
+
+	send(tea.KeyMsg{Type: tea.KeyCtrlG})
+
+	if m.picker == nil {
+		t.Fatal("ctrl+g didn't open the picker")
+	}
+
+	send(tea.KeyMsg{Type: tea.KeyDown})
+	send(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if m.picker != nil || m.selectedHost != web {
+		t.Fatalf("enter in the picker: selected %v, want %s", m.selectedHost, web.Name)
+	}
+
+	if got := m.vars().Identity; got != "/keys/my key" {
+		t.Errorf("identity = %q, want the second key", got)
+	}
+
+	if got, want := m.View(), "Connecting to web1: ssh -i '/keys/my key' web1\n"; got != want {
+		t.Errorf("View() = %q, want %q", got, want)
+	}
+}
+
+func TestIdentityPickerNeedsChoice(t *testing.T) {
+	m := testModel(t, Options{}, func() []*ssh.Host {
+		return []*ssh.Host{{Name: "web1", IdentityFiles: []string{"/keys/work"}}}
+	})
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlG})
+	if m = next.(Model); m.picker != nil || !strings.Contains(m.status, "no identities") {
+		t.Errorf("ctrl+g with one identity: picker %v, status %q", m.picker, m.status)
+	}
+}
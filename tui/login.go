@@ -0,0 +1,64 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pix-xip/pssh/ssh"
+)
+
+// loginPrompt asks for a user to log in to a host as, for one connection.
+type loginPrompt struct {
+	host  *ssh.Host
+	input textinput.Model
+}
+
+// openLoginPrompt asks which user to log in to the host under the cursor as.
+func (m *Model) openLoginPrompt() tea.Cmd {
+	host := m.cursorHost()
+	if host == nil {
+		return nil
+	}
+
+	input := textinput.New()
+	input.Prompt = "Log in to " + host.Name + " as: "
+	input.Placeholder = host.User
+	input.CharLimit = 64
+	input.Focus()
+
+	m.login = &loginPrompt{host: host, input: input}
+
+	return textinput.Blink
+}
+
+// updateLogin handles keys while the login prompt is open. Entering a user
+// selects the host, connecting as that user without changing the host.
+func (m Model) updateLogin(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		m.login = nil
+		return m, nil
+	case "enter":
+		user := strings.TrimSpace(m.login.input.Value())
+		if user == "" {
+			return m, nil
+		}
+
+		host := m.login.host
+		m.loginUser = user
+		m.login = nil
+
+		return m.selectHost(host)
+	}
+
+	var cmd tea.Cmd
+	m.login.input, cmd = m.login.input.Update(msg)
+
+	return m, cmd
+}
+
+// View shows the prompt.
+func (p *loginPrompt) View() string {
+	return pickerStyle.Render(p.input.View() + "\n\nenter to connect • esc to cancel")
+}
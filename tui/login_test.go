@@ -0,0 +1,54 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestLoginPrompt(t *testing.T) {
+	m := configModel(t, Options{}, "Host web1\n  HostName 10.0.0.1\n  User bob\n")
+
+	for _, msg := range []tea.KeyMsg{
+		{Type: tea.KeyCtrlN},
+		{Type: tea.KeyRunes, Runes: []rune("root")},
+		{Type: tea.KeyEnter},
+	} {
+		next, _ := m.Update(msg)
+		m = next.(Model)
+	}
+
+	if m.login != nil {
+		t.Error("login prompt still open after enter")
+	}
+
+	if m.selectedHost == nil || m.selectedHost.Name != "web1" {
+		t.Fatalf("selected %v, want web1", m.selectedHost)
+	}
+
+	if got := m.vars().Override.User; got != "root" {
+		t.Errorf("override user = %q, want root", got)
+	}
+
+	// The host itself is left alone
+	if m.selectedHost.User != "bob" {
+		t.Errorf("host user = %q, want bob", m.selectedHost.User)
+	}
+}
+
+func TestLoginPromptCancelled(t *testing.T) {
+	m := configModel(t, Options{}, "Host web1\n  HostName 10.0.0.1\n")
+
+	for _, msg := range []tea.KeyMsg{
+		{Type: tea.KeyCtrlN},
+		{Type: tea.KeyEnter}, // no user entered yet
+		{Type: tea.KeyEsc},
+	} {
+		next, _ := m.Update(msg)
+		m = next.(Model)
+	}
+
+	if m.login != nil || m.selectedHost != nil || m.loginUser != "" {
+		t.Errorf("cancelled prompt: open %v, selected %v, user %q", m.login != nil, m.selectedHost, m.loginUser)
+	}
+}
@@ -0,0 +1,193 @@
+package tui
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sahilm/fuzzy"
+)
+
+// Matcher is the strategy used to match the search against hosts.
+type Matcher string
+
+const (
+	// MatcherFuzzy matches the first occurrence of each character in turn.
+	MatcherFuzzy Matcher = "fuzzy"
+	// MatcherBoundary finds the best alignment of the search, favouring
+	// characters which start a word, such as after '-', '.', '_' or a
+	// lowercase letter for camelCase.
+	MatcherBoundary Matcher = "boundary"
+)
+
+// ParseMatcher validates a --matcher value.
+func ParseMatcher(s string) (Matcher, error) {
+	switch Matcher(s) {
+	case MatcherFuzzy, MatcherBoundary:
+		return Matcher(s), nil
+	default:
+		return "", fmt.Errorf("invalid matcher %q, must be %q or %q", s, MatcherFuzzy, MatcherBoundary)
+	}
+}
+
+// find returns the targets matching query, best first, with this strategy.
+func (mt Matcher) find(query string, targets []string) fuzzy.Matches {
+	if mt != MatcherBoundary {
+		return fuzzy.Find(query, targets)
+	}
+
+	var ranks fuzzy.Matches
+
+	for i, t := range targets {
+		score, indexes, ok := boundaryMatch(query, t)
+		if !ok {
+			continue
+		}
+
+		ranks = append(ranks, fuzzy.Match{Str: t, Index: i, MatchedIndexes: indexes, Score: score})
+	}
+
+	sort.Stable(ranks)
+
+	return ranks
+}
+
+// Scores for boundaryMatch. A character starting a word is worth more than a
+// run of adjacent ones, so acronyms like "wsp" for web-search-prod win.
+const (
+	matchScore    = 10
+	boundaryBonus = 30
+	adjacentBonus = 15
+	gapPenalty    = 1
+)
+
+// boundaryMatch finds the highest scoring way to match the runes of query,
+// in order and ignoring case, to runes of target. It returns the score and
+// the byte indexes matched, or false if target doesn't contain query.
+func boundaryMatch(query, target string) (int, []int, bool) {
+	q := []rune(query)
+	if len(q) == 0 {
+		return 0, nil, false
+	}
+
+	var (
+		runes    []rune
+		offsets  []int
+		boundary []bool
+	)
+
+	prev := utf8.RuneError
+	for off, r := range target {
+		runes = append(runes, unicode.ToLower(r))
+		offsets = append(offsets, off)
+		boundary = append(boundary, isWordStart(prev, r, off == 0))
+		prev = r
+	}
+
+	n := len(runes)
+	if n < len(q) {
+		return 0, nil, false
+	}
+
+	const none = math.MinInt / 2
+
+	// best[j][i] is the top score for query[:j+1] with query[j] at runes[i],
+	// from[j][i] where query[j-1] was matched for it, and bonuses[j][i] the
+	// word start bonus query[j] scored.
+	best := make([][]int, len(q))
+	from := make([][]int, len(q))
+	bonuses := make([][]int, len(q))
+
+	for j := range q {
+		best[j] = make([]int, n)
+		from[j] = make([]int, n)
+		bonuses[j] = make([]int, n)
+
+		c := unicode.ToLower(q[j])
+
+		// The best predecessor k more than one rune back, less the gap
+		// penalty for the runes skipped: best[j-1][k] - (i-k-1)*gapPenalty.
+		run, runFrom := none, -1
+
+		for i := range n {
+			best[j][i] = none
+
+			if run != none {
+				run -= gapPenalty
+			}
+
+			if j > 0 && i >= 2 && best[j-1][i-2] != none && best[j-1][i-2]-gapPenalty > run {
+				run, runFrom = best[j-1][i-2]-gapPenalty, i-2
+			}
+
+			if runes[i] != c {
+				continue
+			}
+
+			bonus := 0
+			if boundary[i] {
+				bonus = boundaryBonus
+			}
+
+			bonuses[j][i] = bonus
+
+			if j == 0 {
+				best[j][i] = matchScore + bonus
+				continue
+			}
+
+			// A run of adjacent runes keeps the bonus of the rune starting it,
+			// so a whole word scores at least as well as an acronym.
+			adjacent := none
+			if i > 0 && best[j-1][i-1] != none {
+				adjacent = best[j-1][i-1] + adjacentBonus + matchScore + max(bonus, bonuses[j-1][i-1])
+			}
+
+			if adjacent != none && adjacent >= run+matchScore+bonus {
+				best[j][i] = adjacent
+				bonuses[j][i] = max(bonus, bonuses[j-1][i-1])
+				from[j][i] = i - 1
+			} else if run != none {
+				best[j][i] = run + matchScore + bonus
+				from[j][i] = runFrom
+			}
+		}
+	}
+
+	last := len(q) - 1
+
+	end := -1
+	for i := range n {
+		if best[last][i] != none && (end < 0 || best[last][i] > best[last][end]) {
+			end = i
+		}
+	}
+
+	if end < 0 {
+		return 0, nil, false
+	}
+
+	indexes := make([]int, len(q))
+	for j, i := last, end; j >= 0; j-- {
+		indexes[j] = offsets[i]
+		i = from[j][i]
+	}
+
+	return best[last][end], indexes, true
+}
+
+// isWordStart reports whether r starts a word, given the rune before it.
+func isWordStart(prev, r rune, first bool) bool {
+	if first {
+		return true
+	}
+
+	switch prev {
+	case '-', '.', '_', ' ', '/', '@', ':', ',', '(':
+		return true
+	}
+
+	return unicode.IsLower(prev) && unicode.IsUpper(r)
+}
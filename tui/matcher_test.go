@@ -0,0 +1,75 @@
+package tui
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestIsWordStart(t *testing.T) {
+	tests := []struct {
+		prev, r rune
+		first   bool
+		want    bool
+	}{
+		{0, 'w', true, true},
+		{'-', 'a', false, true},
+		{'.', 'a', false, true},
+		{'_', 'a', false, true},
+		{'b', 'a', false, false},
+		// camelCase humps start words, but not runs of capitals
+		{'b', 'A', false, true},
+		{'B', 'A', false, false},
+		{'1', 'a', false, false},
+	}
+
+	for _, tt := range tests {
+		if got := isWordStart(tt.prev, tt.r, tt.first); got != tt.want {
+			t.Errorf("isWordStart(%q, %q, %v) = %v, want %v", tt.prev, tt.r, tt.first, got, tt.want)
+		}
+	}
+}
+
+func TestBoundaryMatch(t *testing.T) {
+	tests := []struct {
+		query, target string
+		want          []int
+	}{
+		// The word "api" rather than the first a, p and i of "rapid"
+		{"api", "rapid-api", []int{6, 7, 8}},
+		{"wsp", "web-search-prod", []int{0, 4, 11}},
+		{"sg", "api.search_gateway", []int{4, 11}},
+		{"gw", "apiGateWay", []int{3, 7}},
+		{"WEB", "web1", []int{0, 1, 2}},
+	}
+
+	for _, tt := range tests {
+		_, got, ok := boundaryMatch(tt.query, tt.target)
+		if !ok || !slices.Equal(got, tt.want) {
+			t.Errorf("boundaryMatch(%q, %q) = %v, %v, want %v", tt.query, tt.target, got, ok, tt.want)
+		}
+	}
+
+	for _, target := range []string{"pia", "ap", ""} {
+		if _, _, ok := boundaryMatch("api", target); ok {
+			t.Errorf("boundaryMatch(api, %q) matched", target)
+		}
+	}
+}
+
+func TestBoundaryBeatsScattered(t *testing.T) {
+	targets := []string{"app-internal", "rapid-api", "capi-gateway", "api"}
+
+	var got []string
+	for _, rank := range MatcherBoundary.find("api", targets) {
+		got = append(got, rank.Str)
+	}
+
+	// Matches at the word "api" rank above those in the middle of words
+	if len(got) != 4 || got[0] != "api" || got[1] != "rapid-api" {
+		t.Errorf("ranked %q, want api and rapid-api first", got)
+	}
+
+	if _, err := ParseMatcher("regex"); err == nil {
+		t.Error("ParseMatcher(regex) succeeded, want an error")
+	}
+}
@@ -4,17 +4,22 @@ package tui
 import (
 	"fmt"
 	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/table"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/pix-xip/pssh/history"
 	"github.com/pix-xip/pssh/ssh"
+	"github.com/pix-xip/pssh/state"
 	"github.com/sahilm/fuzzy"
 )
 
-var baseStyle = lipgloss.NewStyle().BorderForeground(lipgloss.Color("240"))
-
 type Model struct {
 	hosts         []*ssh.Host
 	filteredHosts []*ssh.Host
@@ -24,6 +29,39 @@ type Model struct {
 	width         int
 	height        int
 	selectedHost  *ssh.Host // host for use in connection after selection
+	opts          Options
+	history       queryHistory
+	lastMatch     string                       // last non-empty query which matched any hosts
+	action        Action                       // what enter does with the selected host
+	rowHosts      []*ssh.Host                  // host for each table row, nil for group headers
+	status        string                       // transient message shown in the footer
+	statusID      int                          // identifies status so stale clears are ignored
+	scores        map[*ssh.Host]int            // fuzzy match score per filtered host
+	field         searchField                  // which column the search matches against
+	matches       map[*ssh.Host][]int          // indexes of the search target each host matched at
+	picker        *identityPicker              // open identity picker, if any
+	aliasPicker   *aliasPicker                 // open alias picker, if any
+	alias         string                       // name to connect to the selected host by, if picked
+	identity      ssh.Identity                 // identity chosen for the connection
+	confirmingAll bool                         // asking whether to run the command on every shown host
+	runOnAll      []*ssh.Host                  // hosts to run the command on, once confirmed
+	options       *optionsPane                 // open options pane, if any
+	groupFilter   string                       // only show hosts with this name prefix, if set
+	counts        map[string]int               // connections per host name, when shown
+	hashes        map[string]string            // config block hash per host name, saved for the next run
+	seenHashes    map[string]string            // config block hash per host name as of the last run
+	sort          tableSort                    // column the table is sorted by, if any
+	theme         Theme                        // colours of the table
+	auth          map[*ssh.Host]ssh.AuthMethod // likely authentication per host, when shown
+	columnsMode   bool                         // number keys toggle columns
+	hiddenCols    map[string]bool              // titles of the columns hidden in columns mode
+	preview       bool                         // show the config block of the host under the cursor
+	moreHosts     int                          // filtered hosts left out of the table by MaxHosts
+	login         *loginPrompt                 // open login prompt, if any
+	loginUser     string                       // user to log in as for this connection, if not the host's
+	usage         map[string]history.Stat      // connections per host name, for the usage sorts
+	diagnostics   *diagnosticsPane             // open diagnostics pane, if any
+	help          bool                         // showing the help pane listing the keys
 }
 
 func (m Model) Init() tea.Cmd { return textinput.Blink }
@@ -38,29 +76,168 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.setTableSize(m.width)
 
 	case tea.KeyMsg:
+		if m.picker != nil {
+			return m.updatePicker(msg)
+		}
+
+		if m.aliasPicker != nil {
+			return m.updateAliasPicker(msg)
+		}
+
+		if m.confirmingAll {
+			return m.updateConfirmAll(msg)
+		}
+
+		if m.login != nil {
+			return m.updateLogin(msg)
+		}
+
+		if m.options != nil {
+			return m.updateOptions(msg)
+		}
+
+		if m.diagnostics != nil {
+			return m.updateDiagnostics(msg)
+		}
+
+		if m.help {
+			return m.updateHelp(msg)
+		}
+
+		if m.columnsMode {
+			return m.updateColumns(msg)
+		}
+
+		if m.navigate(msg.String()) {
+			return m, nil
+		}
+
 		switch msg.String() {
 		case "esc", "ctrl+c":
 			if m.textInput.Value() != "" {
 				m.textInput.SetValue("")
-				m.filterHosts()
-				m.table.SetRows(hostsToRows(m.filteredHosts))
+				m.refilter()
+			} else if m.groupFilter != "" {
+				m.groupFilter = ""
+				m.refilter()
 			} else {
 				m.quitting = true
 				return m, tea.Quit
 			}
-		case "enter":
-			selectedRow := m.table.SelectedRow()
-			if len(selectedRow) > 0 {
-				// Find the actual host object from filteredHosts using the selected name
-				for _, h := range m.filteredHosts {
-					if h.Name == selectedRow[0] {
-						m.selectedHost = h
-						break
-					}
+		case "ctrl+o":
+			if m.opts.ReadOnly {
+				return m, m.setStatus("read-only: editing the config is disabled", defaultStatusTTL)
+			}
+
+			return m, m.editConfig()
+		case "alt+e":
+			if m.opts.ReadOnly {
+				return m, m.setStatus("read-only: editing the config is disabled", defaultStatusTTL)
+			}
+
+			return m, m.editHost()
+		case "ctrl+g":
+			return m, m.openIdentityPicker()
+		case "alt+a":
+			return m, m.openAliasPicker()
+		case "ctrl+x":
+			return m, m.confirmRunAll()
+		case "ctrl+y":
+			return m, m.copyCmd(m.opts.Tmpl)
+		case "S":
+			// Only with an empty search, so capital S can still be typed
+			if m.textInput.Value() == "" {
+				return m, m.copyCmd(scpTmpl)
+			}
+		case ".":
+			// Only with an empty search, so dots can still be searched for
+			if m.textInput.Value() == "" {
+				return m, m.filterToGroup()
+			}
+		case "W":
+			if m.textInput.Value() == "" {
+				m.showDiagnostics()
+				return m, nil
+			}
+		case "?":
+			if m.textInput.Value() == "" {
+				m.help = true
+				return m, nil
+			}
+		case "ctrl+s":
+			m.showOptions()
+			return m, nil
+		case "/":
+			// Only with an empty search, so slashes can still be searched for
+			if m.textInput.Value() != "" {
+				break
+			}
+
+			fallthrough
+		case "ctrl+u", "alt+h":
+			m.field = m.field.toggle(fieldKeys[msg.String()])
+			m.refilter()
+			m.table.GotoTop()
+
+			return m, nil
+		case "ctrl+n":
+			return m, m.openLoginPrompt()
+		case "ctrl+p":
+			m.togglePreview()
+			return m, nil
+		case "ctrl+k":
+			m.columnsMode = true
+			return m, nil
+		case "ctrl+l":
+			return m, m.reloadTheme()
+		case "ctrl+r":
+			m.sort = m.sort.next()
+			m.refilter()
+			m.table.GotoTop()
+
+			return m, nil
+		case "ctrl+t":
+			m.action = m.action.toggle()
+			return m, m.setStatus("enter will now "+m.action.String(), defaultStatusTTL)
+		case "tab":
+			// Toggle focus between the table and the search history
+			if m.table.Focused() {
+				m.table.Blur()
+			} else {
+				m.table.Focus()
+			}
+
+			return m, nil
+		case "up", "down":
+			if !m.table.Focused() {
+				var (
+					query string
+					ok    bool
+				)
+
+				if msg.String() == "up" {
+					query, ok = m.history.prev(m.textInput.Value())
+				} else {
+					query, ok = m.history.next()
 				}
+
+				if ok {
+					m.textInput.SetValue(query)
+					m.textInput.CursorEnd()
+					m.refilter()
+					m.table.GotoTop()
+				}
+
+				return m, nil
+			}
+		case "enter":
+			// rowHosts is parallel to the table rows, with nil for group headers
+			cursor := m.table.Cursor()
+			if cursor >= 0 && cursor < len(m.rowHosts) && m.rowHosts[cursor] == nil {
+				return m, nil
 			}
 
-			return m, tea.Quit
+			return m.selectHost(m.cursorHost())
 		}
 
 		// Handle text input and table updates
@@ -70,130 +247,696 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.table, _ = m.table.Update(msg)
 
 		if m.textInput.Value() != oldSearch {
-			m.filterHosts()
-			m.table.SetRows(hostsToRows(m.filteredHosts))
+			m.history.reset()
+			m.refilter()
 			m.table.GotoTop()
+
+			return m, tea.Batch(cmd, m.scheduleRevert(), m.scheduleAutoSelect())
 		}
+
+	case revertSearchMsg:
+		m.revertSearch(msg)
+
+	case autoSelectMsg:
+		return m.autoSelect(msg)
+
+	case clearStatusMsg:
+		m.clearStatus(msg)
+
+	case externalFinishedMsg:
+		return m, m.externalFinished(msg)
 	}
 
 	return m, cmd
 }
 
+// cursorHost returns the host under the cursor, or nil if there isn't one.
+func (m Model) cursorHost() *ssh.Host {
+	cursor := m.table.Cursor()
+	if cursor < 0 || cursor >= len(m.rowHosts) {
+		return nil
+	}
+
+	return m.rowHosts[cursor]
+}
+
+// selectHost finishes the TUI with host selected. A nil host quits.
+func (m Model) selectHost(host *ssh.Host) (tea.Model, tea.Cmd) {
+	if host != nil {
+		m.selectedHost = host
+		if m.alias != "" {
+			m.selectedHost = connectBy(host, m.alias)
+		} else if m.opts.UseMatchedAlias {
+			m.selectedHost = connectAs(m.textInput.Value(), host)
+		}
+	}
+
+	m.history.add(m.textInput.Value())
+
+	m.quitting = true
+
+	return m, tea.Quit
+}
+
+// vars are the template values for the connection, including any choices
+// made in the TUI.
+func (m Model) vars() ssh.CmdVars {
+	vars := m.opts.Vars
+	if m.identity != "" {
+		vars.Identity = m.identity
+	}
+
+	if m.loginUser != "" {
+		vars.Override.User = m.loginUser
+	}
+
+	return vars
+}
+
 func (m Model) View() string {
 	if m.quitting {
-		return "Bye!"
+		return m.quitView()
 	}
 
 	if m.width < 100 {
 		return "Your terminal is too smol! Please resize to at least 100 columns"
 	}
 
+	body := m.theme.baseStyle().Render(m.table.View())
+	if len(m.filteredHosts) == 0 {
+		body = m.emptyView()
+	}
+
+	if m.preview {
+		body = lipgloss.JoinVertical(lipgloss.Left, body, m.previewView())
+	}
+
+	if m.picker != nil {
+		body = m.picker.View()
+	}
+
+	if m.aliasPicker != nil {
+		body = m.aliasPicker.View()
+	}
+
+	if m.options != nil {
+		body = m.options.View()
+	}
+
+	if m.diagnostics != nil {
+		body = m.diagnostics.View(m.diagnosticsHeight())
+	}
+
+	if m.help {
+		body = m.helpView()
+	}
+
+	if m.login != nil {
+		body = m.login.View()
+	}
+
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
 		m.textInput.View(),
-		baseStyle.Render(m.table.View()),
-		"\n Press esc to quit",
+		body,
+		m.footer(),
+	)
+}
+
+// emptyMessage explains why the table is empty: no hosts loaded at all, or
+// none match the search.
+func (m Model) emptyMessage() string {
+	switch {
+	case len(m.hosts) == 0 && m.opts.ChangedOnly:
+		return "No hosts changed since the last run"
+	case len(m.hosts) == 0:
+		return "No SSH hosts found in config"
+	case m.textInput.Value() != "":
+		return fmt.Sprintf("No hosts match '%s'", m.textInput.Value())
+	default:
+		return "No hosts to show"
+	}
+}
+
+// emptyView replaces the table when it has no rows, centred where the table
+// would be so the search and footer don't move.
+func (m Model) emptyView() string {
+	lines := []string{m.emptyMessage(), ""}
+
+	if len(m.hosts) > 0 {
+		lines = append(lines, "esc to clear the search")
+	} else {
+		if !m.opts.ReadOnly {
+			lines = append(lines, "ctrl+o to edit "+m.opts.SSHConfig)
+		}
+
+		if n := len(m.diagnosticList()); n > 0 {
+			lines = append(lines, fmt.Sprintf("W for %d config warnings", n))
+		}
+	}
+
+	box := pickerStyle.Render(strings.Join(lines, "\n"))
+
+	// The height of the table with its border
+	return lipgloss.Place(m.width, m.table.Height()+2, lipgloss.Center, lipgloss.Center, box)
+}
+
+// quitView is the last frame rendered, showing the command about to run for
+// the selected host so it can be checked.
+func (m Model) quitView() string {
+	if len(m.runOnAll) > 0 {
+		return fmt.Sprintf("Running %s on %d hosts\n", m.opts.Vars.Command, len(m.runOnAll))
+	}
+
+	if m.selectedHost == nil {
+		return "Bye!"
+	}
+
+	if m.opts.Tmpl == "" || m.action == ActionPrint {
+		// Nothing will be run, e.g. in print-only mode
+		return ""
+	}
+
+	cmd, err := m.selectedHost.RenderCmd(m.opts.Tmpl, m.vars())
+	if err != nil {
+		return fmt.Sprintf("Connecting to %s (could not render command: %v)\n", m.selectedHost.Name, err)
+	}
+
+	return fmt.Sprintf("Connecting to %s: %s\n", m.selectedHost.Name, cmd)
+}
+
+func (m Model) footer() string {
+	status := ""
+	if m.status != "" {
+		status = " " + statusStyle.Render(m.status)
+	}
+
+	if m.confirmingAll {
+		status = " " + statusStyle.Render(m.confirmAllPrompt())
+	}
+
+	if m.columnsMode {
+		status = " " + statusStyle.Render(m.columnsPrompt())
+	}
+
+	if m.moreHosts > 0 {
+		status = " " + statusStyle.Render(fmt.Sprintf("…and %d more, refine your search", m.moreHosts)) + status
+	}
+
+	if m.groupFilter != "" {
+		status = " " + groupHeaderStyle.Render("group: "+m.groupFilter+" (esc to clear)") + status
+	}
+
+	// The rest of the keys are listed by ?, and hints are dropped from the end
+	// rather than wrapping, so the footer stays on one line.
+	hints := []string{"esc to quit"}
+
+	if m.opts.ReadOnly {
+		hints = append([]string{"🔒 read-only"}, hints...)
+	}
+
+	if m.table.Focused() {
+		hints = append(hints, "? for keys")
+		if n := len(m.diagnosticList()); n > 0 {
+			hints = append(hints, fmt.Sprintf("W for %d config warnings", n))
+		}
+	} else {
+		hints = append(hints, "up/down for search history", "tab to return to hosts")
+	}
+
+	hints = append(hints,
+		fmt.Sprintf("enter to %s (ctrl+t)", m.action),
+		fmt.Sprintf("search: %s (ctrl+u/alt+h, /)", m.field),
+		fmt.Sprintf("sort: %s (ctrl+r)", m.sort),
 	)
+
+	return status + "\n " + fitHints(hints, m.width-1)
+}
+
+// fitHints joins as many of hints as fit in width.
+func fitHints(hints []string, width int) string {
+	const sep = " • "
+
+	line := ""
+	for i, h := range hints {
+		next := h
+		if i > 0 {
+			next = line + sep + h
+		}
+
+		if i > 0 && lipgloss.Width(next) > width {
+			break
+		}
+
+		line = next
+	}
+
+	return line
 }
 
 func (m *Model) setTableSize(width int) {
-	nameWidth := int(float64(width) * 0.25)
-	aliasesWidth := int(float64(width) * 0.20)
-	userWidth := int(float64(width) * 0.1)
-	hostnameWidth := int(float64(width) * 0.35)
-	portWidth := int(float64(width) * 0.08)
-
-	m.table.SetColumns([]table.Column{
-		{Title: "Name", Width: nameWidth},
-		{Title: "Aliases", Width: aliasesWidth},
-		{Title: "User", Width: userWidth},
-		{Title: "Hostname", Width: hostnameWidth},
-		{Title: "Port", Width: portWidth},
-	})
+	// The aliases column grows to fit the longest aliases, up to 40% of the
+	// width, and the other columns share what's left.
+	showAliases := m.opts.AliasFormat != ssh.AliasHidden
+
+	aliasesWidth := 0
+	if showAliases {
+		aliasesWidth = len("Aliases")
+		for _, h := range m.hosts {
+			aliasesWidth = max(aliasesWidth, lipgloss.Width(h.DisplayAliases(m.opts.AliasFormat)))
+		}
+
+		aliasesWidth = min(aliasesWidth+2, int(float64(width)*0.4))
+	}
+
+	rest := float64(width)*0.98 - float64(aliasesWidth)
+
+	const scoreWidth = 7
+	if m.opts.DebugScores {
+		rest -= scoreWidth
+	}
+
+	const countWidth = 6
+	if m.opts.ShowCounts {
+		rest -= countWidth
+	}
+
+	const authWidth = 4
+	if m.opts.ShowAuth {
+		rest -= authWidth
+	}
+
+	const profileWidth = 12
+
+	var descriptionWidth int
+
+	showProfile := m.hasProfiles()
+	if showProfile {
+		rest -= profileWidth
+	}
+
+	showDescription := m.hasDescriptions()
+	if showDescription {
+		descriptionWidth = int(rest * 0.25)
+		rest -= float64(descriptionWidth)
+	}
+
+	var identityWidth int
+	if m.opts.ShowIdentity {
+		identityWidth = int(rest * 0.2)
+		rest -= float64(identityWidth)
+	}
+
+	var sourceWidth int
+	if m.opts.ShowSource {
+		sourceWidth = int(rest * 0.2)
+		rest -= float64(sourceWidth)
+	}
+
+	nameWidth := int(rest * 25 / 78)
+	userWidth := int(rest * 10 / 78)
+	hostnameWidth := int(rest * 35 / 78)
+	portWidth := int(rest * 8 / 78)
+
+	columns := []table.Column{{Title: "Name", Width: nameWidth}}
+	if showAliases {
+		columns = append(columns, table.Column{Title: "Aliases", Width: aliasesWidth})
+	}
+
+	columns = append(columns,
+		table.Column{Title: "User", Width: userWidth},
+		table.Column{Title: "Hostname", Width: hostnameWidth},
+		table.Column{Title: "Port", Width: portWidth},
+	)
+
+	if showDescription {
+		columns = append(columns, table.Column{Title: "Description", Width: descriptionWidth})
+	}
+
+	if m.opts.ShowIdentity {
+		columns = append(columns, table.Column{Title: "Identity", Width: identityWidth})
+	}
+
+	if m.opts.ShowSource {
+		columns = append(columns, table.Column{Title: "Source", Width: sourceWidth})
+	}
+
+	if showProfile {
+		columns = append(columns, table.Column{Title: "Profile", Width: profileWidth})
+	}
+
+	if m.opts.ShowCounts {
+		columns = append(columns, table.Column{Title: "Conns", Width: countWidth})
+	}
+
+	if m.opts.ShowAuth {
+		columns = append(columns, table.Column{Title: "Auth", Width: authWidth})
+	}
+
+	if m.opts.DebugScores {
+		columns = append(columns, table.Column{Title: "Score", Width: scoreWidth})
+	}
+
+	// The old rows may have a cell for a column which is no longer shown, e.g.
+	// after a reload drops the last described host, and rendering them against
+	// the new columns would panic.
+	m.table.SetRows(nil)
+	m.table.SetColumns(m.hideColumns(columns))
+	// Cells are highlighted to fit the column widths, so rebuild them.
+	m.setRows(m.filteredHosts)
 
 	// Subtract space for text input (1 line) and footer (2 lines) and table borders (2 lines)
-	m.table.SetHeight(m.height - 5)
+	height := m.height - 5
+	if m.preview {
+		height -= previewHeight
+	}
+
+	m.table.SetHeight(height)
 	m.textInput.Width = width - 4
 }
 
-func initialModel(fp string) Model {
-	allHosts, err := ssh.LoadSSHConfig([]string{fp})
+func initialModel(opts Options) Model {
+	allHosts, err := opts.LoadHosts()
 	if err != nil {
 		log.Fatal("an error occurred while loading ssh config", "err", err)
 	}
 
+	allHosts = selectableHosts(allHosts)
+
 	tbl := table.New(
 		table.WithFocused(true),
 	)
 
-	s := table.DefaultStyles()
-	s.Header = s.Header.BorderStyle(lipgloss.NormalBorder()).
-		BorderForeground(lipgloss.Color("240")).
-		BorderBottom(true).
-		Bold(false)
-	s.Selected = s.Selected.Foreground(lipgloss.Color("229")).
-		Background(lipgloss.Color("57")).
-		Bold(false)
-
-	tbl.SetStyles(s)
-
 	txtInput := textinput.New()
 	txtInput.Placeholder = "Search SSH hosts..."
 	txtInput.Focus()
 	txtInput.CharLimit = 200
+	txtInput.SetValue(opts.Search)
+
+	st, err := state.Load()
+	if err != nil {
+		st = &state.State{}
+	}
 
 	m := Model{
-		hosts:     allHosts,
-		textInput: txtInput,
-		table:     tbl,
-		height:    20,
+		hashes:     hostHashes(allHosts),
+		seenHashes: st.HostHashes,
+		textInput:  txtInput,
+		table:      tbl,
+		height:     20,
+		opts:       opts,
+		history:    newQueryHistory(st.RecentQueries),
+		action:     opts.Action,
+	}
+
+	m.hosts = m.changedHosts(allHosts)
+
+	if opts.ShowCounts {
+		entries, err := history.Entries()
+		if err != nil {
+			log.Println("could not load history:", err)
+		}
+
+		m.counts = history.Counts(entries)
 	}
 
+	m.resolveAuth()
+
+	m.sort = sorts[opts.Sort]
+	if m.sort.col >= sortRecent {
+		m.usage, err = history.Load()
+		if err != nil {
+			log.Println("could not load history:", err)
+		}
+	}
+
+	m.setTheme(opts.Theme)
 	m.setTableSize(100)
-	m.table.SetRows(hostsToRows(allHosts))
-	m.filterHosts()
+	m.refilter()
+
+	if opts.StartOn == StartOnRecent && st.LastHost != "" {
+		m.moveToHost(st.LastHost)
+	}
 
 	return m
 }
 
+// resolveAuth works out how each host likely authenticates, when shown. It's
+// done up front as resolving every host is too slow to repeat on each
+// keystroke.
+func (m *Model) resolveAuth() {
+	if !m.opts.ShowAuth {
+		return
+	}
+
+	m.auth = make(map[*ssh.Host]ssh.AuthMethod, len(m.hosts))
+	for _, h := range m.hosts {
+		m.auth[h] = h.Auth()
+	}
+}
+
+// selectableHosts drops the pattern hosts, which can't be connected to. Their
+// options still apply to the hosts they match when those are resolved.
+func selectableHosts(hosts []*ssh.Host) []*ssh.Host {
+	return slices.DeleteFunc(hosts, (*ssh.Host).IsPattern)
+}
+
+// changedHosts drops the hosts whose config block is the same as in the last
+// run, when only showing changed hosts.
+func (m *Model) changedHosts(hosts []*ssh.Host) []*ssh.Host {
+	if !m.opts.ChangedOnly {
+		return hosts
+	}
+
+	return slices.DeleteFunc(hosts, func(h *ssh.Host) bool {
+		return m.seenHashes[h.Name] == m.hashes[h.Name]
+	})
+}
+
+// hostHashes returns the config block hash of each host by name.
+func hostHashes(hosts []*ssh.Host) map[string]string {
+	hashes := make(map[string]string, len(hosts))
+	for _, h := range hosts {
+		hashes[h.Name] = h.Hash()
+	}
+
+	return hashes
+}
+
+// refilter re-runs the search and rebuilds the table rows from the result.
+func (m *Model) refilter() {
+	m.filterHosts()
+	m.setRows(m.filteredHosts)
+}
+
+// moveToHost puts the cursor on the row for the named host, reporting
+// whether it was found.
+func (m *Model) moveToHost(name string) bool {
+	for i, h := range m.rowHosts {
+		if h != nil && (h.Name == name || slices.Contains(h.Aliases, name)) {
+			m.table.SetCursor(i)
+			return true
+		}
+	}
+
+	return false
+}
+
+// setRows rebuilds the table from hosts, inserting a header row before each
+// group of hosts when grouping by prefix.
+func (m *Model) setRows(hosts []*ssh.Host) {
+	// Only build rows for the first MaxHosts, until the search narrows enough
+	m.moreHosts = 0
+	if limit := m.opts.MaxHosts; limit > 0 && len(hosts) > limit {
+		m.moreHosts = len(hosts) - limit
+		hosts = hosts[:limit]
+	}
+
+	if !m.opts.GroupByPrefix {
+		m.rowHosts = hosts
+		m.table.SetRows(m.hostsToRows(hosts))
+
+		return
+	}
+
+	var (
+		rows     []table.Row
+		rowHosts []*ssh.Host
+	)
+
+	for _, g := range groupByPrefix(hosts) {
+		rows = append(rows, m.groupHeaderRow(g.prefix))
+		rowHosts = append(rowHosts, nil)
+
+		rows = append(rows, m.hostsToRows(g.hosts)...)
+		rowHosts = append(rowHosts, g.hosts...)
+	}
+
+	m.rowHosts = rowHosts
+	m.table.SetRows(rows)
+}
+
 func (m *Model) filterHosts() {
+	hosts := m.hosts
+	if m.groupFilter != "" {
+		hosts = slices.DeleteFunc(slices.Clone(hosts), func(h *ssh.Host) bool {
+			return hostPrefix(h.Name) != m.groupFilter
+		})
+	}
+
 	searchTerm := m.textInput.Value()
 	if searchTerm == "" {
-		m.filteredHosts = m.hosts
+		if m.sort.col != sortNone {
+			hosts = slices.Clone(hosts)
+			m.sortHosts(hosts)
+		}
+
+		m.filteredHosts = hosts
+		m.scores = nil
+		m.matches = nil
+
 		return
 	}
 
-	targets := make([]string, 0, len(m.hosts))
-	for _, host := range m.hosts {
-		targets = append(targets, fmt.Sprintf("%s %s %s %s %s",
-			host.Name,
-			host.Aliases,
-			host.User,
-			host.Hostname,
-			host.Port,
-		))
+	var ranks fuzzy.Matches
+	if m.opts.FieldScopedSearch {
+		ranks = scopedFind(m.opts.Matcher, searchTerm, hosts, m.field, m.opts.SearchDelimiter)
+	} else {
+		targets := make([]string, 0, len(hosts))
+		for _, host := range hosts {
+			targets = append(targets, m.field.target(host, m.opts.SearchDelimiter))
+		}
+
+		ranks = m.opts.Matcher.find(searchTerm, targets)
 	}
 
-	ranks := fuzzy.Find(searchTerm, targets)
+	newFiltered := make([]*ssh.Host, 0, len(hosts))
+	scores := make(map[*ssh.Host]int, len(ranks))
+	matches := make(map[*ssh.Host][]int, len(ranks))
 
-	newFiltered := make([]*ssh.Host, 0, len(m.hosts))
 	for _, rank := range ranks {
-		newFiltered = append(newFiltered, m.hosts[rank.Index])
+		newFiltered = append(newFiltered, hosts[rank.Index])
+		scores[hosts[rank.Index]] = rank.Score
+		matches[hosts[rank.Index]] = rank.MatchedIndexes
 	}
 
+	m.sortHosts(newFiltered)
+
 	m.filteredHosts = newFiltered
+	m.scores = scores
+	m.matches = matches
+
+	if len(newFiltered) > 0 {
+		m.lastMatch = searchTerm
+	}
 }
 
-func hostsToRows(hosts []*ssh.Host) []table.Row {
+// hasProfiles reports whether hosts were loaded from profiles, and so need a
+// column showing which.
+func (m *Model) hasProfiles() bool {
+	return len(m.hosts) > 0 && m.hosts[0].Profile != ""
+}
+
+// hasDescriptions reports whether any host has a description, and so needs a
+// column showing them.
+func (m *Model) hasDescriptions() bool {
+	return slices.ContainsFunc(m.hosts, func(h *ssh.Host) bool { return h.Description != "" })
+}
+
+// displayHostname is the hostname shown for host: the canonical name ssh will
+// use, when it extends the configured one so search matches still line up.
+func displayHostname(host *ssh.Host) string {
+	if strings.HasPrefix(host.CanonicalHostname, host.Hostname) {
+		return host.CanonicalHostname
+	}
+
+	return host.Hostname
+}
+
+// displaySource is where host is defined, e.g. "~/.ssh/config:12", with the
+// home directory shortened so the file name is less likely to be cut off.
+func displaySource(host *ssh.Host) string {
+	if host.SourceFile == "" {
+		return ""
+	}
+
+	file := host.SourceFile
+	if home, err := os.UserHomeDir(); err == nil {
+		if rest, ok := strings.CutPrefix(file, home+string(filepath.Separator)); ok {
+			file = "~/" + rest
+		}
+	}
+
+	return fmt.Sprintf("%s:%d", file, host.SourceLine)
+}
+
+func (m *Model) hostsToRows(hosts []*ssh.Host) []table.Row {
+	showDescription := m.hasDescriptions()
+	showAliases := m.opts.AliasFormat != ssh.AliasHidden
+
+	widths := make(map[string]int)
+	for _, c := range m.table.Columns() {
+		widths[c.Title] = c.Width
+	}
+
 	rows := make([]table.Row, 0, len(hosts))
 	for _, host := range hosts {
-		rows = append(rows, table.Row{
-			host.Name,
-			host.Aliases,
-			host.User,
-			host.Hostname,
-			host.Port,
-		})
+		hl := splitMatches(m.field.parts(host), m.opts.SearchDelimiter, m.matches[host])
+
+		marker := ""
+		if n := len(host.Forwards); n > 0 {
+			marker = fmt.Sprintf(" ⇄%d", n)
+		}
+
+		name := highlight(host.DisplayName(m.opts.ShortNameSuffix), hl[colName], widths["Name"]-lipgloss.Width(marker)) + marker
+
+		row := table.Row{name}
+		if showAliases {
+			aliases := host.DisplayAliases(m.opts.AliasFormat)
+			idx := aliasIndexes(searchAliases(host), host.Aliases, aliasLead(m.opts.AliasFormat), hl[colAliases])
+			row = append(row, highlight(aliases, idx, widths["Aliases"]))
+		}
+
+		row = append(row,
+			highlight(host.User, hl[colUser], widths["User"]),
+			highlight(displayHostname(host), hl[colHostname], widths["Hostname"]),
+			highlight(host.Port, hl[colPort], widths["Port"]),
+		)
+
+		if showDescription {
+			row = append(row, highlight(host.Description, hl[colDescription], widths["Description"]))
+		}
+
+		if m.opts.ShowIdentity {
+			row = append(row, highlight(identities(host), hl[colIdentity], widths["Identity"]))
+		}
+
+		if m.opts.ShowSource {
+			row = append(row, displaySource(host))
+		}
+
+		if m.hasProfiles() {
+			row = append(row, highlight(host.Profile, hl[colProfile], widths["Profile"]))
+		}
+
+		if m.opts.ShowCounts {
+			row = append(row, strconv.Itoa(m.counts[host.Name]))
+		}
+
+		if m.opts.ShowAuth {
+			row = append(row, m.auth[host].Badge())
+		}
+
+		if m.opts.DebugScores {
+			score := ""
+			if s, ok := m.scores[host]; ok {
+				score = strconv.Itoa(s)
+			}
+
+			row = append(row, score)
+		}
+
+		rows = append(rows, row)
 	}
 
 	return rows
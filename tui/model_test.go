@@ -0,0 +1,666 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pix-xip/pssh/ssh"
+	"github.com/pix-xip/pssh/state"
+)
+
+func TestQuitView(t *testing.T) {
+	opts := Options{
+		Tmpl: "ssh {{.ExtraArgs}} {{.Name}}",
+		Vars: ssh.CmdVars{ExtraArgs: ssh.Args{"-L", "8080:localhost:80"}},
+	}
+
+	m := Model{opts: opts, quitting: true}
+	if got := m.View(); got != "Bye!" {
+		t.Errorf("View() with no host = %q, want %q", got, "Bye!")
+	}
+
+	m.selectedHost = &ssh.Host{Name: "web1"}
+
+	want := "Connecting to web1: ssh -L 8080:localhost:80 web1\n"
+	if got := m.View(); got != want {
+		t.Errorf("View() = %q, want %q", got, want)
+	}
+
+	m.opts.Tmpl = "ssh {{.Missing}}"
+	if got := m.View(); !strings.Contains(got, "could not render command") {
+		t.Errorf("View() with a bad template = %q, want the render error", got)
+	}
+}
+
+func TestQuitViewPrintOnly(t *testing.T) {
+	m := Model{quitting: true, selectedHost: &ssh.Host{Name: "web1"}}
+
+	// Nothing is run in print-only mode, so the last frame is left empty.
+	if got := m.View(); got != "" {
+		t.Errorf("View() = %q, want nothing", got)
+	}
+}
+
+// configModel builds a model over the hosts in config, with state kept out of
+// the user's own.
+func configModel(t *testing.T, opts Options, config string) Model {
+	t.Helper()
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	opts.SSHConfig = path
+	opts.LoadHosts = func() ([]*ssh.Host, error) {
+		return ssh.LoadSSHConfig([]string{path}, ssh.LoadOptions{})
+	}
+
+	return initialModel(opts)
+}
+
+func TestRevertSearch(t *testing.T) {
+	const config = "Host web1\n  HostName 10.0.0.1\nHost db1\n  HostName 10.0.0.2\n"
+
+	m := configModel(t, Options{RevertSearchAfter: time.Second}, config)
+
+	m.textInput.SetValue("web")
+	m.refilter()
+
+	m.textInput.SetValue("webqq")
+	m.refilter()
+
+	if len(m.filteredHosts) != 0 {
+		t.Fatalf("%q matched %d hosts, want none", "webqq", len(m.filteredHosts))
+	}
+
+	if m.scheduleRevert() == nil {
+		t.Fatal("no revert scheduled for a query matching nothing")
+	}
+
+	if m.revertSearch(revertSearchMsg{query: "other"}) {
+		t.Error("reverted a search edited since the revert was scheduled")
+	}
+
+	if !m.revertSearch(revertSearchMsg{query: "webqq"}) {
+		t.Fatal("didn't revert the search")
+	}
+
+	if got := m.textInput.Value(); got != "web" || len(m.filteredHosts) != 1 {
+		t.Errorf("reverted to %q matching %d hosts, want %q matching 1", got, len(m.filteredHosts), "web")
+	}
+
+	m.opts.RevertSearchAfter = 0
+	m.textInput.SetValue("webqq")
+	m.refilter()
+
+	if m.scheduleRevert() != nil {
+		t.Error("revert scheduled while disabled")
+	}
+}
+
+func TestAliasesColumnWidth(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   int
+	}{
+		{"no aliases", "Host web1\n", len("Aliases") + 2},
+		{"fits", "Host web1 alpha beta\n", len("(alpha, beta)") + 2},
+		{"capped", "Host web1 " + strings.Repeat("a", 60) + "\n", 40},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := configModel(t, Options{}, tt.config)
+
+			if got := m.table.Columns()[1].Width; got != tt.want {
+				t.Errorf("Aliases column width = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToggleAction(t *testing.T) {
+	m := configModel(t, Options{Tmpl: "ssh {{.Name}}"}, "Host web1\n")
+
+	// The footer drops hints which don't fit its width
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 20})
+	m = next.(Model)
+
+	for _, want := range []Action{ActionPrint, ActionConnect, ActionPrint} {
+		next, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+		m = next.(Model)
+
+		if m.action != want {
+			t.Fatalf("action = %v, want %v", m.action, want)
+		}
+
+		if footer := m.footer(); !strings.Contains(footer, "enter to "+want.String()) {
+			t.Errorf("footer %q doesn't show %q", footer, want)
+		}
+	}
+
+	// Printing runs nothing, so the last frame is left empty.
+	m.quitting = true
+	m.selectedHost = &ssh.Host{Name: "web1"}
+
+	if got := m.View(); got != "" {
+		t.Errorf("View() when printing = %q, want nothing", got)
+	}
+}
+
+func TestStartOnRecent(t *testing.T) {
+	const config = "Host web1\n  HostName 10.0.0.1\nHost db1\n  HostName 10.0.0.2\nHost cache1\n  HostName 10.0.0.3\n"
+
+	m := configModel(t, Options{StartOn: StartOnRecent}, config)
+
+	// Save a selection as the last run would have
+	m.selectedHost = &ssh.Host{Name: "db1"}
+	saveState(m)
+
+	if got := initialModel(m.opts); got.rowHosts[got.table.Cursor()].Name != "db1" {
+		t.Errorf("started on %s, want the last selected host", got.rowHosts[got.table.Cursor()].Name)
+	}
+
+	m.opts.StartOn = StartOnFirst
+	if got := initialModel(m.opts).table.Cursor(); got != 0 {
+		t.Errorf("start on first: cursor = %d, want 0", got)
+	}
+
+	st, err := state.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	st.LastHost = "gone"
+	if err := st.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	m.opts.StartOn = StartOnRecent
+	if got := initialModel(m.opts).table.Cursor(); got != 0 {
+		t.Errorf("start on a removed host: cursor = %d, want the first row", got)
+	}
+}
+
+func TestDebugScores(t *testing.T) {
+	m := configModel(t, Options{DebugScores: true}, "Host web1\n  HostName 10.0.0.1\nHost db1\n  HostName 10.0.0.2\n")
+
+	cols := m.table.Columns()
+	if last := cols[len(cols)-1]; last.Title != "Score" {
+		t.Fatalf("last column = %q, want Score", last.Title)
+	}
+
+	// No scores without a search
+	for _, row := range m.table.Rows() {
+		if score := row[len(row)-1]; score != "" {
+			t.Errorf("score %q with an empty search, want blank", score)
+		}
+	}
+
+	m.textInput.SetValue("web")
+	m.refilter()
+
+	rows := m.table.Rows()
+	if len(rows) != 1 {
+		t.Fatalf("got %d rows, want 1", len(rows))
+	}
+
+	if _, err := strconv.Atoi(rows[0][len(rows[0])-1]); err != nil {
+		t.Errorf("score %q isn't a number", rows[0][len(rows[0])-1])
+	}
+
+	if m := configModel(t, Options{}, "Host web1\n"); len(m.table.Columns()) != 5 {
+		t.Errorf("got %d columns without --debug-scores, want 5", len(m.table.Columns()))
+	}
+}
+
+// testModel builds a model over the hosts returned by load, with state kept
+// out of the user's own.
+func testModel(t *testing.T, opts Options, load func() []*ssh.Host) Model {
+	t.Helper()
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	opts.LoadHosts = func() ([]*ssh.Host, error) { return load(), nil }
+
+	return initialModel(opts)
+}
+
+func TestProfileColumn(t *testing.T) {
+	m := testModel(t, Options{}, func() []*ssh.Host {
+		return []*ssh.Host{{Name: "build1", Profile: "work"}, {Name: "nas", Profile: "home"}}
+	})
+
+	cols := m.table.Columns()
+	if cols[len(cols)-1].Title != "Profile" {
+		t.Fatalf("last column = %q, want Profile", cols[len(cols)-1].Title)
+	}
+
+	m.textInput.SetValue("home")
+	m.refilter()
+
+	if len(m.filteredHosts) != 1 || m.filteredHosts[0].Name != "nas" {
+		t.Errorf("searching a profile matched %v, want nas", m.filteredHosts)
+	}
+
+	m = testModel(t, Options{}, func() []*ssh.Host { return []*ssh.Host{{Name: "web1"}} })
+	for _, c := range m.table.Columns() {
+		if c.Title == "Profile" {
+			t.Error("Profile column shown without profiles")
+		}
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	m := configModel(t, Options{ReadOnly: true}, "Host web1\n")
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	if m = next.(Model); !strings.Contains(m.status, "read-only") {
+		t.Errorf("ctrl+o when read-only: status = %q, want it refused", m.status)
+	}
+
+	if footer := m.footer(); !strings.Contains(footer, "read-only") || strings.Contains(footer, "ctrl+o") {
+		t.Errorf("footer %q, want the lock and no ctrl+o hint", footer)
+	}
+
+	m = configModel(t, Options{}, "Host web1\n")
+
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	if m = next.(Model); cmd == nil || m.status != "" {
+		t.Errorf("ctrl+o: status = %q, want the editor started", m.status)
+	}
+}
+
+func TestAliasFormatHidden(t *testing.T) {
+	m := configModel(t, Options{AliasFormat: ssh.AliasHidden}, "Host web1 alpha\n  User root\n")
+
+	for _, c := range m.table.Columns() {
+		if c.Title == "Aliases" {
+			t.Fatal("Aliases column shown with the hidden format")
+		}
+	}
+
+	if row := m.table.Rows()[0]; len(row) != len(m.table.Columns()) || row[1] != "root" {
+		t.Errorf("row = %q, want the user after the name", row)
+	}
+}
+
+func TestShowCounts(t *testing.T) {
+	m := configModel(t, Options{ShowCounts: true}, "Host web1\n")
+
+	cols := m.table.Columns()
+	if last := cols[len(cols)-1]; last.Title != "Conns" {
+		t.Fatalf("last column = %q, want Conns", last.Title)
+	}
+
+	// Nothing has been recorded in the temporary state dir
+	if row := m.table.Rows()[0]; row[len(row)-1] != "0" {
+		t.Errorf("count = %q, want 0", row[len(row)-1])
+	}
+}
+
+func TestPatternHostsHidden(t *testing.T) {
+	const config = "Host web1\n  HostName 10.0.0.1\nHost *.internal\n  User deploy\nHost web-?\n  Port 2222\nHost db1 !db2\n  HostName 10.0.0.2\n"
+
+	m := configModel(t, Options{}, config)
+
+	var names []string
+	for _, h := range m.hosts {
+		names = append(names, h.Name)
+	}
+
+	slices.Sort(names)
+
+	if want := []string{"db1", "web1"}; !slices.Equal(names, want) {
+		t.Errorf("hosts = %q, want the patterns hidden leaving %q", names, want)
+	}
+}
+
+func TestMaxHosts(t *testing.T) {
+	var config strings.Builder
+	for i := range 50 {
+		fmt.Fprintf(&config, "Host h%d\n  HostName 10.0.0.%d\n", i, i)
+	}
+
+	m := configModel(t, Options{MaxHosts: 10}, config.String())
+
+	tests := []struct {
+		search string
+		rows   int
+		more   int
+	}{
+		{"", 10, 40},
+		{"h4", 10, 4},
+		{"h49", 1, 0},
+	}
+
+	for _, tt := range tests {
+		m.textInput.SetValue(tt.search)
+		m.refilter()
+
+		if got := len(m.table.Rows()); got != tt.rows || m.moreHosts != tt.more {
+			t.Errorf("search %q shows %d rows and %d more, want %d and %d", tt.search, got, m.moreHosts, tt.rows, tt.more)
+		}
+
+		want := fmt.Sprintf("…and %d more", tt.more)
+		if got := strings.Contains(m.footer(), want); got != (tt.more > 0) {
+			t.Errorf("search %q: footer %q, want %q shown only when hosts are left out", tt.search, m.footer(), want)
+		}
+	}
+}
+
+func TestInitialSearch(t *testing.T) {
+	m := configModel(t, Options{Search: "web"}, "Host web1\n  HostName 10.0.0.1\nHost db1\n  HostName 10.0.0.2\n")
+
+	if got := m.textInput.Value(); got != "web" {
+		t.Errorf("search = %q, want web", got)
+	}
+
+	if len(m.filteredHosts) != 1 || m.filteredHosts[0].Name != "web1" {
+		t.Errorf("filtered to %d hosts, want only web1", len(m.filteredHosts))
+	}
+}
+
+func TestAutoSelect(t *testing.T) {
+	const config = "Host web1\n  HostName 10.0.0.1\nHost web2\n  HostName 10.0.0.2\nHost db1\n  HostName 10.0.0.3\n"
+
+	m := configModel(t, Options{AutoSelect: true}, config)
+
+	m.textInput.SetValue("web")
+	m.refilter()
+
+	if m.scheduleAutoSelect() != nil {
+		t.Error("auto-select scheduled for a search matching two hosts")
+	}
+
+	m.textInput.SetValue("db")
+	m.refilter()
+
+	if m.scheduleAutoSelect() == nil {
+		t.Fatal("no auto-select scheduled for a search matching one host")
+	}
+
+	// The search was edited after the tick was scheduled
+	next, _ := m.autoSelect(autoSelectMsg{query: "d"})
+	if next.(Model).selectedHost != nil {
+		t.Error("auto-selected after the search changed")
+	}
+
+	m.columnsMode = true
+	next, _ = m.autoSelect(autoSelectMsg{query: "db"})
+	if next.(Model).selectedHost != nil {
+		t.Error("auto-selected with a prompt open")
+	}
+
+	m.columnsMode = false
+	next, _ = m.autoSelect(autoSelectMsg{query: "db"})
+	if got := next.(Model).selectedHost; got == nil || got.Name != "db1" {
+		t.Errorf("auto-selected %v, want db1", got)
+	}
+
+	m.opts.AutoSelect = false
+	if m.scheduleAutoSelect() != nil {
+		t.Error("auto-select scheduled while disabled")
+	}
+}
+
+func TestEmptyView(t *testing.T) {
+	m := configModel(t, Options{}, "")
+
+	view := m.emptyView()
+	for _, want := range []string{"No SSH hosts found in config", "ctrl+o to edit " + m.opts.SSHConfig} {
+		if !strings.Contains(view, want) {
+			t.Errorf("empty view %q doesn't mention %q", view, want)
+		}
+	}
+
+	m.opts.ReadOnly = true
+	if view := m.emptyView(); strings.Contains(view, "ctrl+o") {
+		t.Errorf("read-only empty view %q offers to edit the config", view)
+	}
+}
+
+func TestEmptyMessage(t *testing.T) {
+	const config = "Host web1\n  HostName 10.0.0.1\n"
+
+	tests := []struct {
+		name   string
+		opts   Options
+		config string
+		search string
+		want   string
+	}{
+		{"no hosts", Options{}, "", "", "No SSH hosts found in config"},
+		{"nothing changed", Options{ChangedOnly: true}, "", "", "No hosts changed since the last run"},
+		{"no match", Options{}, config, "xyz", "No hosts match 'xyz'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := configModel(t, tt.opts, tt.config)
+			m.textInput.SetValue(tt.search)
+			m.refilter()
+
+			if got := m.emptyMessage(); got != tt.want {
+				t.Errorf("emptyMessage() = %q, want %q", got, tt.want)
+			}
+
+			if tt.search != "" && !strings.Contains(m.emptyView(), "esc to clear the search") {
+				t.Error("empty view doesn't offer to clear the search")
+			}
+		})
+	}
+}
+
+func TestShowSource(t *testing.T) {
+	t.Setenv("HOME", "/home/me")
+
+	for _, tt := range []struct {
+		host *ssh.Host
+		want string
+	}{
+		{&ssh.Host{SourceFile: "/home/me/.ssh/config", SourceLine: 12}, "~/.ssh/config:12"},
+		{&ssh.Host{SourceFile: "/etc/ssh/ssh_config", SourceLine: 3}, "/etc/ssh/ssh_config:3"},
+		{&ssh.Host{SourceFile: "/home/meta/config", SourceLine: 1}, "/home/meta/config:1"},
+		{&ssh.Host{}, ""},
+	} {
+		if got := displaySource(tt.host); got != tt.want {
+			t.Errorf("displaySource(%s) = %q, want %q", tt.host.SourceFile, got, tt.want)
+		}
+	}
+
+	m := configModel(t, Options{ShowSource: true}, "Host web1\n  HostName 10.0.0.1\n")
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 20})
+	m = next.(Model)
+
+	var titles []string
+	for _, c := range m.table.Columns() {
+		titles = append(titles, c.Title)
+	}
+
+	i := slices.Index(titles, "Source")
+	if i < 0 {
+		t.Fatalf("columns = %q, want a Source column", titles)
+	}
+
+	if want := m.opts.SSHConfig + ":1"; m.table.Rows()[0][i] != want {
+		t.Errorf("source = %q, want %q", m.table.Rows()[0][i], want)
+	}
+}
+
+func TestReloadDroppingColumn(t *testing.T) {
+	tests := []struct {
+		name   string
+		before []*ssh.Host
+		after  []*ssh.Host
+	}{
+		{
+			"last description",
+			[]*ssh.Host{{Name: "web1", Description: "front end"}, {Name: "db1"}},
+			[]*ssh.Host{{Name: "db1"}},
+		},
+		{
+			"profiles",
+			[]*ssh.Host{{Name: "web1", Profile: "work"}},
+			[]*ssh.Host{{Name: "web1"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loads := [][]*ssh.Host{tt.before, tt.after}
+			m := testModel(t, Options{}, func() []*ssh.Host {
+				hosts := loads[0]
+				loads = loads[1:]
+
+				return hosts
+			})
+
+			m.reload()
+
+			if got := len(m.table.Columns()); got != 5 {
+				t.Errorf("reload left %d columns, want 5", got)
+			}
+
+			if got := m.table.SelectedRow(); len(got) != len(m.table.Columns()) {
+				t.Errorf("selected row has %d cells for %d columns", len(got), len(m.table.Columns()))
+			}
+
+			_ = m.View()
+		})
+	}
+}
+
+func TestSearchKeysTyped(t *testing.T) {
+	// Keys which act only on an empty search are typed into a search in
+	// progress.
+	for _, key := range []string{"S", ".", "W", "/", "?"} {
+		t.Run(key, func(t *testing.T) {
+			m := testModel(t, Options{}, func() []*ssh.Host { return []*ssh.Host{{Name: "web1"}} })
+			m.textInput.SetValue("we")
+
+			updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+
+			if got := updated.(Model).textInput.Value(); got != "we"+key {
+				t.Errorf("search after typing %s = %q, want %q", key, got, "we"+key)
+			}
+		})
+	}
+}
+
+func TestReloadKeepsChangedFilter(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	path := filepath.Join(t.TempDir(), "config")
+	writeConfig := func(web1User string) {
+		config := "Host web1\n  User " + web1User + "\n\nHost db1\n  User db\n\nHost cache\n  User cache\n"
+		if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	load := func() ([]*ssh.Host, error) { return ssh.LoadSSHConfig([]string{path}, ssh.LoadOptions{}) }
+
+	// As of the last run, web1 and cache were unchanged and db1 is new
+	writeConfig("bob")
+
+	hosts, err := load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hashes := hostHashes(hosts)
+	delete(hashes, "db1")
+
+	st := &state.State{HostHashes: hashes}
+	if err := st.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	m := initialModel(Options{LoadHosts: load, ChangedOnly: true})
+	if got := hostNames(m.hosts); !slices.Equal(got, []string{"db1"}) {
+		t.Fatalf("changed hosts = %q, want [db1]", got)
+	}
+
+	writeConfig("alice")
+	m.reload()
+
+	if got := hostNames(m.hosts); !slices.Equal(got, []string{"web1", "db1"}) {
+		t.Errorf("changed hosts after reload = %q, want [web1 db1]", got)
+	}
+}
+
+func TestFooterFits(t *testing.T) {
+	diags := []ssh.Diagnostic{{File: "config", Line: 1, Message: "unknown option Foo"}}
+	opts := Options{
+		ReadOnly:    true,
+		Vars:        ssh.CmdVars{Command: "uptime"},
+		Diagnostics: func() []ssh.Diagnostic { return diags },
+	}
+
+	for _, width := range []int{100, 140, 300} {
+		m := testModel(t, opts, func() []*ssh.Host { return []*ssh.Host{{Name: "web1"}} })
+		m.width = width
+
+		hints := strings.Split(m.footer(), "\n")[1]
+		if got := lipgloss.Width(hints); got > width {
+			t.Errorf("hints are %d wide in %d columns: %s", got, width, hints)
+		}
+
+		if !strings.Contains(hints, "? for keys") {
+			t.Errorf("hints in %d columns don't mention ?: %s", width, hints)
+		}
+	}
+}
+
+func TestHelpPane(t *testing.T) {
+	m := testModel(t, Options{}, func() []*ssh.Host { return []*ssh.Host{{Name: "web1"}} })
+	m.width = 120
+
+	press := func(key string) {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		if key == "esc" {
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		}
+
+		updated, _ := m.Update(msg)
+		m = updated.(Model)
+	}
+
+	press("?")
+
+	if !m.help {
+		t.Fatal("? didn't open the help pane")
+	}
+
+	if view := m.View(); !strings.Contains(view, "alt+e") || !strings.Contains(view, "edit the host's block") {
+		t.Errorf("help pane doesn't list alt+e:\n%s", view)
+	}
+
+	// Typing is ignored while the pane is open
+	press("x")
+
+	if !m.help || m.textInput.Value() != "" {
+		t.Errorf("typing with the help pane open: help %v, search %q", m.help, m.textInput.Value())
+	}
+
+	press("esc")
+
+	if m.help {
+		t.Error("esc didn't close the help pane")
+	}
+
+	if m.quitting {
+		t.Error("esc closing the help pane also quit")
+	}
+}
@@ -0,0 +1,29 @@
+package tui
+
+// navigate moves the cursor for the vim-style navigation keys, reporting
+// whether key was one. Plain letters are search text, so the letters are
+// used with alt: alt+j and alt+k move a row, alt+g and alt+G jump to the
+// first and last rows, and alt+d (or ctrl+d, as in vim) and alt+u move half
+// a page. ctrl+u is left scoping the search to the user.
+func (m *Model) navigate(key string) bool {
+	half := max(m.table.Height()/2, 1)
+
+	switch key {
+	case "alt+j":
+		m.table.MoveDown(1)
+	case "alt+k":
+		m.table.MoveUp(1)
+	case "alt+g":
+		m.table.GotoTop()
+	case "alt+G":
+		m.table.GotoBottom()
+	case "alt+d", "ctrl+d":
+		m.table.MoveDown(half)
+	case "alt+u":
+		m.table.MoveUp(half)
+	default:
+		return false
+	}
+
+	return true
+}
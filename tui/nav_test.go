@@ -0,0 +1,49 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestNavigate(t *testing.T) {
+	var config strings.Builder
+	for i := range 50 {
+		fmt.Fprintf(&config, "Host h%02d\n  HostName 10.0.0.%d\n", i, i)
+	}
+
+	m := configModel(t, Options{}, config.String())
+	half := max(m.table.Height()/2, 1)
+
+	tests := []struct {
+		key  string
+		want int
+	}{
+		{"alt+j", 1},
+		{"alt+j", 2},
+		{"alt+k", 1},
+		{"alt+G", 49},
+		{"alt+g", 0},
+		{"alt+d", half},
+		{"ctrl+d", 2 * half},
+		{"alt+u", half},
+	}
+
+	for _, tt := range tests {
+		if !m.navigate(tt.key) {
+			t.Fatalf("%s isn't a navigation key", tt.key)
+		}
+
+		if got := m.table.Cursor(); got != tt.want {
+			t.Errorf("after %s: cursor = %d, want %d", tt.key, got, tt.want)
+		}
+	}
+
+	// Plain letters are typed into the search
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	if m = next.(Model); m.textInput.Value() != "j" {
+		t.Errorf("search = %q after typing j, want j", m.textInput.Value())
+	}
+}
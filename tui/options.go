@@ -0,0 +1,78 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pix-xip/pssh/ssh"
+)
+
+// optionsPane shows the options ssh would use for a host, to check them
+// before connecting.
+type optionsPane struct {
+	host *ssh.Host
+	// sorted lists the options alphabetically rather than in config order.
+	sorted bool
+}
+
+// showOptions opens the options pane for the host under the cursor.
+func (m *Model) showOptions() {
+	host := m.cursorHost()
+	if host == nil {
+		return
+	}
+
+	m.options = &optionsPane{host: host}
+}
+
+// updateOptions handles keys while the options pane is open: s toggles the
+// sort order and anything else closes it.
+func (m Model) updateOptions(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.String() == "s" {
+		m.options.sorted = !m.options.sorted
+		return m, nil
+	}
+
+	m.options = nil
+
+	return m, nil
+}
+
+// resolved returns the host's options in the chosen order.
+func (p *optionsPane) resolved() []ssh.Option {
+	opts := p.host.Resolve()
+	if p.sorted {
+		// Stable, so repeated options such as IdentityFile keep their order.
+		slices.SortStableFunc(opts, func(a, b ssh.Option) int {
+			return strings.Compare(strings.ToLower(a.Key), strings.ToLower(b.Key))
+		})
+	}
+
+	return opts
+}
+
+// View lists the options with their values aligned.
+func (p *optionsPane) View() string {
+	opts := p.resolved()
+
+	width := 0
+	for _, o := range opts {
+		width = max(width, len(o.Key))
+	}
+
+	order := "config order"
+	if p.sorted {
+		order = "alphabetical"
+	}
+
+	lines := []string{fmt.Sprintf("Options for %s (%s):", p.host.Name, order), ""}
+	for _, o := range opts {
+		lines = append(lines, fmt.Sprintf("%-*s  %s", width, o.Key, o.Value))
+	}
+
+	lines = append(lines, "", "ssh's defaults apply to anything not listed", "s to change order • any key to close")
+
+	return pickerStyle.Render(strings.Join(lines, "\n"))
+}
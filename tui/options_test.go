@@ -0,0 +1,54 @@
+package tui
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestOptionsPane(t *testing.T) {
+	m := configModel(t, Options{}, "Host web1\n  User deploy\n  HostName 10.0.0.1\n  IdentityFile ~/.ssh/b\n  IdentityFile ~/.ssh/a\n")
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	m = next.(Model)
+
+	if m.options == nil {
+		t.Fatal("ctrl+s didn't open the options pane")
+	}
+
+	keys := func() []string {
+		var keys []string
+		for _, o := range m.options.resolved() {
+			keys = append(keys, o.Key+" "+o.Value)
+		}
+
+		return keys
+	}
+
+	configOrder := []string{"User deploy", "HostName 10.0.0.1", "IdentityFile ~/.ssh/b", "IdentityFile ~/.ssh/a"}
+	if got := keys(); !slices.Equal(got, configOrder) {
+		t.Errorf("options = %q, want config order %q", got, configOrder)
+	}
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	m = next.(Model)
+
+	// Repeated options keep their order when sorted
+	sorted := []string{"HostName 10.0.0.1", "IdentityFile ~/.ssh/b", "IdentityFile ~/.ssh/a", "User deploy"}
+	if got := keys(); !slices.Equal(got, sorted) {
+		t.Errorf("options after s = %q, want alphabetical %q", got, sorted)
+	}
+
+	if !strings.Contains(m.options.View(), "(alphabetical)") {
+		t.Error("pane doesn't say it's sorted alphabetically")
+	}
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = next.(Model)
+
+	if m.options != nil {
+		t.Error("esc didn't close the options pane")
+	}
+}
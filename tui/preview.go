@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// previewHeight is how many lines the preview pane takes, including its
+// border.
+const previewHeight = 8
+
+var previewStyle = lipgloss.NewStyle().
+	BorderStyle(lipgloss.NormalBorder()).
+	BorderTop(true).
+	BorderForeground(lipgloss.Color("240")).
+	PaddingLeft(1)
+
+// togglePreview shows or hides the preview pane, making room for it in the
+// table.
+func (m *Model) togglePreview() {
+	m.preview = !m.preview
+	m.setTableSize(m.width)
+}
+
+// previewView shows the options set in the config block of the host under
+// the cursor, following it as it moves.
+func (m Model) previewView() string {
+	lines := m.previewLines(previewHeight - 1)
+
+	return previewStyle.Width(m.width).Height(previewHeight - 1).Render(strings.Join(lines, "\n"))
+}
+
+// previewLines are the lines of the preview, at most height of them.
+func (m Model) previewLines(height int) []string {
+	host := m.cursorHost()
+	if host == nil {
+		return []string{"No host selected"}
+	}
+
+	opts := host.Options()
+	if len(opts) == 0 {
+		return []string{fmt.Sprintf("Host %s sets no options", host.Name)}
+	}
+
+	width := 0
+	for _, o := range opts {
+		width = max(width, len(o.Key))
+	}
+
+	lines := []string{"Host " + host.Name}
+	for i, o := range opts {
+		if len(lines) == height-1 && i < len(opts)-1 {
+			lines = append(lines, fmt.Sprintf("… %d more, ctrl+s for all", len(opts)-i))
+			break
+		}
+
+		lines = append(lines, fmt.Sprintf("  %-*s  %s", width, o.Key, o.Value))
+	}
+
+	return lines
+}
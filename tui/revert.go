@@ -0,0 +1,41 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// revertSearchMsg fires after Options.RevertSearchAfter when query matched
+// no hosts.
+type revertSearchMsg struct {
+	query string
+}
+
+// scheduleRevert returns a tick to revert the search if the current query
+// matches nothing and reverting is enabled.
+func (m Model) scheduleRevert() tea.Cmd {
+	query := m.textInput.Value()
+	if m.opts.RevertSearchAfter <= 0 || query == "" || len(m.filteredHosts) > 0 {
+		return nil
+	}
+
+	return tea.Tick(m.opts.RevertSearchAfter, func(time.Time) tea.Msg {
+		return revertSearchMsg{query: query}
+	})
+}
+
+// revertSearch restores the last matching query, unless the search has been
+// edited since the revert was scheduled. It reports whether it reverted.
+func (m *Model) revertSearch(msg revertSearchMsg) bool {
+	if m.textInput.Value() != msg.query || len(m.filteredHosts) > 0 || m.lastMatch == "" {
+		return false
+	}
+
+	m.textInput.SetValue(m.lastMatch)
+	m.textInput.CursorEnd()
+	m.refilter()
+	m.table.GotoTop()
+
+	return true
+}
@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/sahilm/fuzzy"
+
+	"github.com/pix-xip/pssh/ssh"
+)
+
+// scopedFind matches query against hosts like Matcher.find, but each
+// whitespace separated word must match within a single part of the target, so
+// no match spans a field boundary. A host's score is the sum of its words'
+// best scores, and matched indexes are into the parts joined by sep.
+func scopedFind(mt Matcher, query string, hosts []*ssh.Host, field searchField, sep string) fuzzy.Matches {
+	words := strings.Fields(query)
+
+	var ranks fuzzy.Matches
+
+	for i, host := range hosts {
+		parts := field.parts(host)
+
+		texts := make([]string, len(parts))
+		starts := make([]int, len(parts))
+
+		start := 0
+		for j, p := range parts {
+			texts[j] = p.text
+			starts[j] = start
+			start += len(p.text) + len(sep)
+		}
+
+		rank := fuzzy.Match{Index: i, Str: strings.Join(texts, sep)}
+		matched := make(map[int]bool)
+
+		for _, w := range words {
+			best := mt.find(w, texts)
+			if len(best) == 0 {
+				matched = nil
+				break
+			}
+
+			rank.Score += best[0].Score
+			for _, idx := range best[0].MatchedIndexes {
+				matched[starts[best[0].Index]+idx] = true
+			}
+		}
+
+		if matched == nil {
+			continue
+		}
+
+		for idx := range matched {
+			rank.MatchedIndexes = append(rank.MatchedIndexes, idx)
+		}
+
+		sort.Ints(rank.MatchedIndexes)
+		ranks = append(ranks, rank)
+	}
+
+	sort.Stable(ranks)
+
+	return ranks
+}
@@ -0,0 +1,64 @@
+package tui
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pix-xip/pssh/ssh"
+	"github.com/sahilm/fuzzy"
+)
+
+func TestScopedFind(t *testing.T) {
+	hosts := []*ssh.Host{
+		{Name: "web1", Hostname: "github.com"},
+		{Name: "db1", Hostname: "10.0.0.2"},
+	}
+
+	// Across the fields "b1gi" matches web1 github.com, but not within one
+	if ranks := fuzzy.Find("b1gi", []string{fieldAll.target(hosts[0], " ")}); len(ranks) != 1 {
+		t.Fatalf("b1gi matched %d targets across fields, want 1", len(ranks))
+	}
+
+	if ranks := scopedFind(MatcherFuzzy, "b1gi", hosts, fieldAll, " "); len(ranks) != 0 {
+		t.Errorf("b1gi matched %d hosts within fields, want none", len(ranks))
+	}
+
+	ranks := scopedFind(MatcherFuzzy, "web1 github", hosts, fieldAll, " ")
+	if len(ranks) != 1 || ranks[0].Index != 0 {
+		t.Fatalf("web1 github matched %v, want only web1", ranks)
+	}
+
+	// Indexes are into the fields joined by the delimiter
+	target := fieldAll.target(hosts[0], " ")
+
+	var matched []byte
+	for _, i := range ranks[0].MatchedIndexes {
+		matched = append(matched, target[i])
+	}
+
+	if got := string(matched); got != "web1github" {
+		t.Errorf("matched %q of %q, want web1github", got, target)
+	}
+}
+
+func TestSearchDelimiter(t *testing.T) {
+	host := &ssh.Host{Name: "web1", User: "bob", Hostname: "10.0.0.1"}
+	parts := fieldAll.parts(host)
+
+	target := fieldAll.target(host, " | ")
+	if want := "web1 |  | bob | 10.0.0.1 | "; !strings.HasPrefix(target, want) {
+		t.Fatalf("target = %q, want it to start %q", target, want)
+	}
+
+	// "b" and "1" of "bob | 10" map back to the user and hostname columns
+	ranks := fuzzy.Find("bob10", []string{target})
+	if len(ranks) != 1 {
+		t.Fatalf("bob10 matched %d targets, want 1", len(ranks))
+	}
+
+	got := splitMatches(parts, " | ", ranks[0].MatchedIndexes)
+	if !slices.Equal(got[colUser], []int{0, 1, 2}) || !slices.Equal(got[colHostname], []int{0, 1}) {
+		t.Errorf("splitMatches = %v, want bob in the user and 10 in the hostname", got)
+	}
+}
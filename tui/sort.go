@@ -0,0 +1,179 @@
+package tui
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pix-xip/pssh/ssh"
+)
+
+// sortColumn is the column the table is sorted by.
+type sortColumn int
+
+const (
+	sortNone sortColumn = iota // search rank, or config order
+	sortName
+	sortUser
+	sortHostname
+	sortPort
+	sortDomain // hostname by domain, then subdomain
+	// The usage sorts are only chosen with --sort, not cycled through.
+	sortRecent   // most recently connected first
+	sortFrequent // most connected first
+)
+
+func (c sortColumn) String() string {
+	switch c {
+	case sortName:
+		return "name"
+	case sortUser:
+		return "user"
+	case sortHostname:
+		return "hostname"
+	case sortPort:
+		return "port"
+	case sortDomain:
+		return "domain"
+	case sortRecent:
+		return "recent"
+	case sortFrequent:
+		return "frequent"
+	default:
+		return "none"
+	}
+}
+
+// tableSort is the active sort of the table.
+type tableSort struct {
+	col sortColumn
+	asc bool
+}
+
+// next cycles through each column ascending then descending, and back to
+// unsorted.
+func (s tableSort) next() tableSort {
+	switch {
+	case s.col == sortNone:
+		return tableSort{col: sortName, asc: true}
+	case s.col >= sortRecent:
+		return tableSort{}
+	case s.asc:
+		return tableSort{col: s.col}
+	case s.col == sortDomain:
+		return tableSort{}
+	default:
+		return tableSort{col: s.col + 1, asc: true}
+	}
+}
+
+func (s tableSort) String() string {
+	if s.col == sortNone || s.col >= sortRecent {
+		return s.col.String()
+	}
+
+	if s.asc {
+		return s.col.String() + " ↑"
+	}
+
+	return s.col.String() + " ↓"
+}
+
+// SortBy is the order the table starts in.
+type SortBy string
+
+// sorts are the tableSort for each SortBy, ascending except for the usage
+// sorts, which put the most used first.
+var sorts = map[SortBy]tableSort{
+	"":         {},
+	"name":     {col: sortName, asc: true},
+	"user":     {col: sortUser, asc: true},
+	"hostname": {col: sortHostname, asc: true},
+	"port":     {col: sortPort, asc: true},
+	"domain":   {col: sortDomain, asc: true},
+	"recent":   {col: sortRecent},
+	"frequent": {col: sortFrequent},
+}
+
+// ParseSortBy validates a --sort value.
+func ParseSortBy(s string) (SortBy, error) {
+	if _, ok := sorts[SortBy(s)]; !ok {
+		return "", fmt.Errorf("invalid sort %q, must be one of name, user, hostname, port, domain, recent or frequent", s)
+	}
+
+	return SortBy(s), nil
+}
+
+// sortHosts sorts hosts in place by the active sort, using usage for the usage
+// sorts.
+func (m *Model) sortHosts(hosts []*ssh.Host) {
+	switch m.sort.col {
+	case sortRecent:
+		slices.SortStableFunc(hosts, func(a, b *ssh.Host) int {
+			return m.usage[b.Name].Last.Compare(m.usage[a.Name].Last)
+		})
+	case sortFrequent:
+		slices.SortStableFunc(hosts, func(a, b *ssh.Host) int {
+			return cmp.Compare(m.usage[b.Name].Count, m.usage[a.Name].Count)
+		})
+	default:
+		sortHosts(hosts, m.sort.col, m.sort.asc)
+	}
+}
+
+// sortHosts stably sorts hosts in place by col. Ports compare numerically,
+// with an unset port being ssh's default of 22.
+func sortHosts(hosts []*ssh.Host, col sortColumn, asc bool) {
+	if col == sortNone {
+		return
+	}
+
+	slices.SortStableFunc(hosts, func(a, b *ssh.Host) int {
+		var c int
+
+		switch col {
+		case sortName:
+			c = strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+		case sortUser:
+			c = strings.Compare(strings.ToLower(a.User), strings.ToLower(b.User))
+		case sortHostname:
+			c = strings.Compare(strings.ToLower(a.Hostname), strings.ToLower(b.Hostname))
+		case sortPort:
+			c = cmp.Compare(portNumber(a.Port), portNumber(b.Port))
+		case sortDomain:
+			c = strings.Compare(domainSortKey(a.Hostname), domainSortKey(b.Hostname))
+		}
+
+		if !asc {
+			c = -c
+		}
+
+		return c
+	})
+}
+
+// portNumber is port as a number for sorting, 22 if unset and after every
+// valid port if it isn't a number.
+func portNumber(port string) int {
+	if port == "" {
+		return 22
+	}
+
+	n, err := strconv.Atoi(port)
+	if err != nil {
+		return 1 << 16
+	}
+
+	return n
+}
+
+// domainSortKey is hostname with its labels reversed, e.g. "com.example.a"
+// for "a.example.com", so hosts sort by domain and then subdomain.
+func domainSortKey(hostname string) string {
+	labels := strings.Split(strings.ToLower(strings.TrimSuffix(hostname, ".")), ".")
+	slices.Reverse(labels)
+
+	return strings.Join(labels, ".")
+}
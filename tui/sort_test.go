@@ -0,0 +1,117 @@
+package tui
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/pix-xip/pssh/history"
+	"github.com/pix-xip/pssh/ssh"
+)
+
+// hostNames returns the name of each host, in order.
+func hostNames(hosts []*ssh.Host) []string {
+	names := make([]string, len(hosts))
+	for i, h := range hosts {
+		names[i] = h.Name
+	}
+
+	return names
+}
+
+func TestSortHosts(t *testing.T) {
+	hosts := []*ssh.Host{
+		{Name: "web2", User: "deploy", Hostname: "b.example.com", Port: "2222"},
+		{Name: "Web1", User: "Admin", Hostname: "a.example.org"},
+		{Name: "db1", User: "deploy", Hostname: "db.example.com", Port: "80"},
+		{Name: "cache", User: "", Hostname: "c.example.net", Port: "ssh"},
+	}
+
+	tests := []struct {
+		col  sortColumn
+		asc  bool
+		want []string
+	}{
+		{sortNone, true, []string{"web2", "Web1", "db1", "cache"}},
+		{sortName, true, []string{"cache", "db1", "Web1", "web2"}},
+		{sortName, false, []string{"web2", "Web1", "db1", "cache"}},
+		// Equal users keep their config order, whichever the direction
+		{sortUser, true, []string{"cache", "Web1", "web2", "db1"}},
+		{sortUser, false, []string{"web2", "db1", "Web1", "cache"}},
+		{sortHostname, true, []string{"Web1", "web2", "cache", "db1"}},
+		// An unset port is 22, and one which isn't a number goes last
+		{sortPort, true, []string{"Web1", "db1", "web2", "cache"}},
+		{sortDomain, true, []string{"web2", "db1", "cache", "Web1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tableSort{col: tt.col, asc: tt.asc}.String(), func(t *testing.T) {
+			sorted := slices.Clone(hosts)
+			sortHosts(sorted, tt.col, tt.asc)
+
+			if got := hostNames(sorted); !slices.Equal(got, tt.want) {
+				t.Errorf("sorted = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTableSortNext(t *testing.T) {
+	var got []string
+	for s := (tableSort{col: sortName, asc: true}); s.col != sortNone; s = s.next() {
+		got = append(got, s.String())
+	}
+
+	want := []string{"name ↑", "name ↓", "user ↑", "user ↓", "hostname ↑", "hostname ↓", "port ↑", "port ↓", "domain ↑", "domain ↓"}
+	if !slices.Equal(got, want) {
+		t.Errorf("cycled through %q, want %q", got, want)
+	}
+
+	if next := sorts["recent"].next(); next != (tableSort{}) {
+		t.Errorf("next after recent = %s, want none", next)
+	}
+}
+
+func TestDomainSortKey(t *testing.T) {
+	for hostname, want := range map[string]string{
+		"a.example.com":  "com.example.a",
+		"A.Example.com.": "com.example.a",
+		"localhost":      "localhost",
+		"":               "",
+	} {
+		if got := domainSortKey(hostname); got != want {
+			t.Errorf("domainSortKey(%q) = %q, want %q", hostname, got, want)
+		}
+	}
+}
+
+func TestSortHostsByUsage(t *testing.T) {
+	now := time.Now()
+	hosts := []*ssh.Host{{Name: "web1"}, {Name: "db1"}, {Name: "cache"}, {Name: "web2"}}
+	usage := map[string]history.Stat{
+		"web1": {Count: 2, Last: now.Add(-time.Hour)},
+		"db1":  {Count: 5, Last: now.Add(-time.Minute)},
+		"web2": {Count: 2, Last: now},
+	}
+
+	tests := []struct {
+		sort SortBy
+		want []string
+	}{
+		{"recent", []string{"web2", "db1", "web1", "cache"}},
+		{"frequent", []string{"db1", "web1", "web2", "cache"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.sort), func(t *testing.T) {
+			m := Model{sort: sorts[tt.sort], usage: usage}
+
+			sorted := slices.Clone(hosts)
+			m.sortHosts(sorted)
+
+			if got := hostNames(sorted); !slices.Equal(got, tt.want) {
+				t.Errorf("sorted = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
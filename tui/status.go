@@ -0,0 +1,39 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// defaultStatusTTL is how long status messages show for unless overridden.
+const defaultStatusTTL = 3 * time.Second
+
+var statusStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("220"))
+
+// clearStatusMsg clears the status message it was scheduled for, unless a
+// newer one has replaced it since.
+type clearStatusMsg struct {
+	id int
+}
+
+// setStatus shows msg in the footer, returning a tick which clears it after
+// ttl.
+func (m *Model) setStatus(msg string, ttl time.Duration) tea.Cmd {
+	m.statusID++
+	m.status = msg
+
+	id := m.statusID
+
+	return tea.Tick(ttl, func(time.Time) tea.Msg {
+		return clearStatusMsg{id: id}
+	})
+}
+
+// clearStatus handles a clearStatusMsg, leaving newer messages in place.
+func (m *Model) clearStatus(msg clearStatusMsg) {
+	if msg.id == m.statusID {
+		m.status = ""
+	}
+}
@@ -0,0 +1,35 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatus(t *testing.T) {
+	var m Model
+
+	if cmd := m.setStatus("copied", time.Millisecond); cmd == nil {
+		t.Fatal("setStatus returned no tick to clear it")
+	}
+
+	first := clearStatusMsg{id: m.statusID}
+
+	if !strings.Contains(m.footer(), "copied") {
+		t.Errorf("footer %q doesn't show the status", m.footer())
+	}
+
+	// A newer message isn't cleared by the older one's tick
+	m.setStatus("reloaded", time.Millisecond)
+	m.clearStatus(first)
+
+	if m.status != "reloaded" {
+		t.Errorf("status = %q after a stale clear, want the newer message kept", m.status)
+	}
+
+	m.clearStatus(clearStatusMsg{id: m.statusID})
+
+	if m.status != "" {
+		t.Errorf("status = %q, want it cleared", m.status)
+	}
+}
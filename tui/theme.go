@@ -0,0 +1,76 @@
+package tui
+
+import (
+	"cmp"
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme is the TUI's colours, as ANSI 256 colour numbers or hex codes. Unset
+// colours use the defaults.
+type Theme struct {
+	Border             string
+	SelectedForeground string
+	SelectedBackground string
+}
+
+var defaultTheme = Theme{
+	Border:             "240",
+	SelectedForeground: "229",
+	SelectedBackground: "57",
+}
+
+// withDefaults fills in the unset colours of t.
+func (t Theme) withDefaults() Theme {
+	return Theme{
+		Border:             cmp.Or(t.Border, defaultTheme.Border),
+		SelectedForeground: cmp.Or(t.SelectedForeground, defaultTheme.SelectedForeground),
+		SelectedBackground: cmp.Or(t.SelectedBackground, defaultTheme.SelectedBackground),
+	}
+}
+
+// baseStyle is the style of the border around the table.
+func (t Theme) baseStyle() lipgloss.Style {
+	return lipgloss.NewStyle().BorderForeground(lipgloss.Color(t.Border))
+}
+
+// tableStyles are the table's styles in t's colours.
+func (t Theme) tableStyles() table.Styles {
+	s := table.DefaultStyles()
+	s.Header = s.Header.BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color(t.Border)).
+		BorderBottom(true).
+		Bold(false)
+	s.Selected = s.Selected.Foreground(lipgloss.Color(t.SelectedForeground)).
+		Background(lipgloss.Color(t.SelectedBackground)).
+		Bold(false)
+
+	return s
+}
+
+// setTheme restyles the table in t's colours.
+func (m *Model) setTheme(t Theme) {
+	m.theme = t.withDefaults()
+	m.table.SetStyles(m.theme.tableStyles())
+}
+
+// reloadTheme rereads the theme with Options.ReloadTheme and applies it.
+func (m *Model) reloadTheme() tea.Cmd {
+	if m.opts.ReloadTheme == nil {
+		return nil
+	}
+
+	t, err := m.opts.ReloadTheme()
+	if err != nil {
+		return m.setStatus(fmt.Sprintf("could not reload theme: %v", err), defaultStatusTTL)
+	}
+
+	m.setTheme(t)
+	// Rebuild the columns and rows in the new styles
+	m.setTableSize(m.width)
+
+	return m.setStatus("theme reloaded", defaultStatusTTL)
+}
@@ -0,0 +1,44 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestReloadTheme(t *testing.T) {
+	next := Theme{SelectedBackground: "212"}
+
+	var reloadErr error
+
+	opts := Options{
+		Theme:       Theme{Border: "99"},
+		ReloadTheme: func() (Theme, error) { return next, reloadErr },
+	}
+
+	m := configModel(t, opts, "Host web1\n")
+	if m.theme.Border != "99" || m.theme.SelectedBackground != defaultTheme.SelectedBackground {
+		t.Fatalf("theme = %+v, want the border set and the rest defaulted", m.theme)
+	}
+
+	update, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlL})
+	m = update.(Model)
+
+	want := Theme{Border: defaultTheme.Border, SelectedForeground: defaultTheme.SelectedForeground, SelectedBackground: "212"}
+	if m.theme != want {
+		t.Errorf("theme after ctrl+l = %+v, want %+v", m.theme, want)
+	}
+
+	// A failed reload keeps the current theme
+	reloadErr = errors.New("bad toml")
+	next = Theme{Border: "1"}
+
+	update, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlL})
+	m = update.(Model)
+
+	if m.theme != want || !strings.Contains(m.status, "bad toml") {
+		t.Errorf("after a failed reload theme = %+v with status %q", m.theme, m.status)
+	}
+}
@@ -1,21 +1,221 @@
 package tui
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"log"
+	"os"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-isatty"
 
 	"github.com/pix-xip/pssh/ssh"
+	"github.com/pix-xip/pssh/state"
 )
 
-func SelectHost(fp string) *ssh.Host {
-	m := initialModel(fp)
-	p := tea.NewProgram(m)
+// Action is what to do with the selected host once the TUI exits.
+type Action int
+
+const (
+	// ActionConnect connects to the selected host.
+	ActionConnect Action = iota
+	// ActionPrint prints the command for the selected host instead.
+	ActionPrint
+)
+
+func (a Action) String() string {
+	if a == ActionPrint {
+		return "print command"
+	}
+
+	return "connect"
+}
+
+// toggle flips between connecting and printing.
+func (a Action) toggle() Action {
+	if a == ActionPrint {
+		return ActionConnect
+	}
+
+	return ActionPrint
+}
+
+// StartOn is which row the cursor starts on when the TUI opens.
+type StartOn string
+
+const (
+	// StartOnFirst starts on the first row.
+	StartOnFirst StartOn = "first"
+	// StartOnRecent starts on the most recently selected host, if any.
+	StartOnRecent StartOn = "recent"
+)
+
+// ParseStartOn validates a --start-on value.
+func ParseStartOn(s string) (StartOn, error) {
+	switch StartOn(s) {
+	case StartOnFirst, StartOnRecent:
+		return StartOn(s), nil
+	default:
+		return "", fmt.Errorf("invalid start position %q, must be %q or %q", s, StartOnFirst, StartOnRecent)
+	}
+}
+
+// Options configures how the TUI loads and displays hosts.
+type Options struct {
+	// SSHConfig is the path to the primary ssh config file.
+	SSHConfig string
+	// LoadHosts loads the hosts to choose from.
+	LoadHosts func() ([]*ssh.Host, error)
+	// Diagnostics, if set, returns the problems found by the last call to
+	// LoadHosts, shown with W.
+	Diagnostics func() []ssh.Diagnostic
+	// Tmpl is the command template run for the selected host.
+	Tmpl string
+	// Vars are the extra values available to Tmpl.
+	Vars ssh.CmdVars
+	// Action is the initial action taken on enter, toggled with ctrl+t.
+	Action Action
+	// Output is where the TUI is rendered, defaulting to stdout.
+	Output io.Writer
+	// StartOn is which row the cursor starts on.
+	StartOn StartOn
+	// Search is the search the TUI starts with.
+	Search string
+	// GroupByPrefix groups hosts under a header by the name prefix before the
+	// first '-'.
+	GroupByPrefix bool
+	// RevertSearchAfter restores the last matching search after this long when
+	// the query matches nothing. Zero disables it.
+	RevertSearchAfter time.Duration
+	// AutoSelect selects the host as soon as the search matches only it, once
+	// the search has settled.
+	AutoSelect bool
+	// ChangedOnly shows only hosts whose config block was added or changed
+	// since the last run.
+	ChangedOnly bool
+	// ShowCounts adds a column with how many times each host was connected to.
+	ShowCounts bool
+	// Sort is the order the table starts in, changed with ctrl+r.
+	Sort SortBy
+	// MaxHosts caps how many hosts are shown in the table, to keep it quick
+	// with enormous configs. Zero shows every host.
+	MaxHosts int
+	// ShowAuth adds a column with a badge for whether each host likely uses a
+	// key or a password.
+	ShowAuth bool
+	// ShowIdentity adds a column with the identity files of each host.
+	ShowIdentity bool
+	// ShowSource adds a column with the file and line each host is defined
+	// at.
+	ShowSource bool
+	// DebugScores adds a column with each row's fuzzy match score.
+	DebugScores bool
+	// Matcher is how the search is matched against hosts, defaulting to
+	// MatcherFuzzy.
+	Matcher Matcher
+	// SearchDelimiter joins a host's fields into the text searched.
+	SearchDelimiter string
+	// FieldScopedSearch requires each word of the search to match within a
+	// single field, rather than across field boundaries.
+	FieldScopedSearch bool
+	// ShortNameSuffix is stripped from host names in the table, if set.
+	ShortNameSuffix string
+	// UseMatchedAlias connects using the alias the search matched, rather than
+	// the host's name, when an alias matched best.
+	UseMatchedAlias bool
+	// AliasFormat is how aliases are shown, hiding the column for
+	// ssh.AliasHidden.
+	AliasFormat ssh.AliasFormat
+	// Theme is the table's colours.
+	Theme Theme
+	// ReloadTheme, if set, rereads the theme when ctrl+l is pressed.
+	ReloadTheme func() (Theme, error)
+	// ReadOnly disables the keys which change the ssh config, e.g. ctrl+o.
+	ReadOnly bool
+}
+
+// ErrNoTTY is returned by SelectHost when it isn't attached to a terminal.
+var ErrNoTTY = errors.New("the host selector needs a terminal, use --exec to connect directly or --print-only to capture the selected host")
+
+// Selection is the outcome of the TUI.
+type Selection struct {
+	// Host is the selected host, or nil if the user quit.
+	Host *ssh.Host
+	// Action is what to do with Host.
+	Action Action
+	// Vars are Options.Vars with any choices made for this connection, e.g.
+	// the identity.
+	Vars ssh.CmdVars
+	// All holds every host shown when the user chose to run the command on
+	// all of them, instead of selecting Host.
+	All []*ssh.Host
+}
+
+// SelectHost runs the TUI and returns the selected host and what to do with it.
+func SelectHost(opts Options) (Selection, error) {
+	out := opts.Output
+	if out == nil {
+		out = os.Stdout
+	}
+
+	if err := checkTTY(os.Stdin, out); err != nil {
+		return Selection{Action: opts.Action, Vars: opts.Vars}, err
+	}
+
+	m := initialModel(opts)
+
+	var progOpts []tea.ProgramOption
+	if opts.Output != nil {
+		progOpts = append(progOpts, tea.WithOutput(opts.Output))
+	}
+
+	p := tea.NewProgram(m, progOpts...)
 
 	final, err := p.Run()
 	if err != nil {
-		log.Fatal("error running program", "err", err)
+		return Selection{Action: opts.Action, Vars: opts.Vars}, fmt.Errorf("error running program: %w", err)
 	}
 
-	return final.(Model).selectedHost
+	fm := final.(Model)
+	saveState(fm)
+
+	return Selection{Host: fm.selectedHost, Action: fm.action, Vars: fm.vars(), All: fm.runOnAll}, nil
+}
+
+// checkTTY returns ErrNoTTY if any of the streams backed by a file aren't a
+// terminal. Other readers and writers are assumed to know what they're doing.
+func checkTTY(streams ...any) error {
+	for _, s := range streams {
+		f, ok := s.(*os.File)
+		if !ok {
+			continue
+		}
+
+		if !isatty.IsTerminal(f.Fd()) && !isatty.IsCygwinTerminal(f.Fd()) {
+			return fmt.Errorf("%w (%s is not a terminal)", ErrNoTTY, f.Name())
+		}
+	}
+
+	return nil
+}
+
+// saveState persists the recent search queries and selected host. Failing to
+// do so shouldn't stop a connection, so errors are only logged.
+func saveState(m Model) {
+	st, err := state.Load()
+	if err != nil {
+		log.Println("could not load state:", err)
+		return
+	}
+
+	st.RecentQueries = m.history.entries
+	st.HostHashes = m.hashes
+	if m.selectedHost != nil {
+		st.LastHost = m.selectedHost.Name
+	}
+	if err := st.Save(); err != nil {
+		log.Println("could not save state:", err)
+	}
 }
@@ -0,0 +1,48 @@
+package tui
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCheckTTY(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = r.Close()
+		_ = w.Close()
+	})
+
+	err = checkTTY(r, w)
+	if !errors.Is(err, ErrNoTTY) {
+		t.Fatalf("checkTTY(pipe) = %v, want ErrNoTTY", err)
+	}
+
+	if !strings.Contains(err.Error(), r.Name()) {
+		t.Errorf("error %q doesn't name the stream", err)
+	}
+
+	// Streams that aren't files are left to the caller.
+	if err := checkTTY(strings.NewReader(""), &strings.Builder{}); err != nil {
+		t.Errorf("checkTTY(non-file streams) = %v, want nil", err)
+	}
+}
+
+func TestParseStartOn(t *testing.T) {
+	for _, s := range []string{"first", "recent"} {
+		if got, err := ParseStartOn(s); err != nil || string(got) != s {
+			t.Errorf("ParseStartOn(%q) = %q, %v", s, got, err)
+		}
+	}
+
+	for _, s := range []string{"", "last", "Recent"} {
+		if _, err := ParseStartOn(s); err == nil {
+			t.Errorf("ParseStartOn(%q) succeeded, want an error", s)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"cmp"
+	"net"
+	"time"
+
+	"github.com/charmbracelet/log"
+
+	"github.com/pix-xip/pssh/ssh"
+)
+
+const (
+	defaultWatchInterval = 5 * time.Second
+	// dialTimeout is how long each reachability check waits for the host to
+	// accept a connection.
+	dialTimeout = 3 * time.Second
+)
+
+// dialer opens a connection, as net.DialTimeout does.
+type dialer func(network, address string, timeout time.Duration) (net.Conn, error)
+
+// waitForHost waits until host accepts connections on the address ssh would
+// connect to with vars, checking every interval. Hosts behind a proxy can't be
+// reached directly to check on, so aren't waited for.
+func waitForHost(host *ssh.Host, vars ssh.CmdVars, interval time.Duration, dial dialer) {
+	if host.IsProxied() || vars.Jump != "" {
+		log.Warn("can't check whether a host behind a proxy is up, connecting anyway", "host", host.Name)
+		return
+	}
+
+	waitReachable(host, watchAddr(host, vars.Override.Port), interval, dial)
+}
+
+// watchAddr is the address ssh would connect to for host, with port
+// overriding the host's own.
+func watchAddr(host *ssh.Host, port string) string {
+	return net.JoinHostPort(cmp.Or(host.Hostname, host.Name), cmp.Or(port, host.Port, "22"))
+}
+
+// waitReachable polls addr every interval until it accepts a connection,
+// logging while it waits.
+func waitReachable(host *ssh.Host, addr string, interval time.Duration, dial dialer) {
+	for attempt := 1; ; attempt++ {
+		conn, err := dial("tcp", addr, dialTimeout)
+		if err == nil {
+			_ = conn.Close()
+			log.Infof("%s is up, connecting…", host.Name)
+
+			return
+		}
+
+		log.Infof("%s (%s) is unreachable (check %d), checking again in %s…", host.Name, addr, attempt, interval)
+		time.Sleep(interval)
+	}
+}
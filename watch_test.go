@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/pix-xip/pssh/ssh"
+)
+
+// stubDialer fails the first down dials, then connects, recording each
+// address dialled.
+type stubDialer struct {
+	down  int
+	addrs []string
+}
+
+func (d *stubDialer) dial(_, address string, _ time.Duration) (net.Conn, error) {
+	d.addrs = append(d.addrs, address)
+
+	if len(d.addrs) <= d.down {
+		return nil, errors.New("connection refused")
+	}
+
+	client, server := net.Pipe()
+	_ = server.Close()
+
+	return client, nil
+}
+
+func TestWaitForHost(t *testing.T) {
+	tests := []struct {
+		name  string
+		host  *ssh.Host
+		vars  ssh.CmdVars
+		down  int
+		addrs []string
+	}{
+		{"up", &ssh.Host{Name: "web1", Hostname: "10.0.0.1"}, ssh.CmdVars{}, 0, []string{"10.0.0.1:22"}},
+		{
+			"comes up",
+			&ssh.Host{Name: "web1", Hostname: "10.0.0.1", Port: "2222"},
+			ssh.CmdVars{},
+			2,
+			[]string{"10.0.0.1:2222", "10.0.0.1:2222", "10.0.0.1:2222"},
+		},
+		{
+			"port override",
+			&ssh.Host{Name: "web1", Port: "2222"},
+			ssh.CmdVars{Override: ssh.Override{Port: "2200"}},
+			0,
+			[]string{"web1:2200"},
+		},
+		{"ipv6", &ssh.Host{Name: "v6", Hostname: "::1"}, ssh.CmdVars{}, 0, []string{"[::1]:22"}},
+		// Proxied hosts can't be dialled directly, so aren't waited for
+		{"proxy command", &ssh.Host{Name: "web1", ProxyCommand: "nc %h %p"}, ssh.CmdVars{}, 5, nil},
+		{"jump", &ssh.Host{Name: "web1"}, ssh.CmdVars{Jump: "bastion"}, 5, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &stubDialer{down: tt.down}
+			waitForHost(tt.host, tt.vars, time.Millisecond, d.dial)
+
+			if len(d.addrs) != len(tt.addrs) {
+				t.Fatalf("dialled %q, want %q", d.addrs, tt.addrs)
+			}
+
+			for i := range d.addrs {
+				if d.addrs[i] != tt.addrs[i] {
+					t.Errorf("dialled %q, want %q", d.addrs, tt.addrs)
+					break
+				}
+			}
+		})
+	}
+}